@@ -0,0 +1,49 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/bep/simplecobra"
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestLegacyHugoFlagsAreRegistered(t *testing.T) {
+	c := qt.New(t)
+
+	registered := make(map[string]bool)
+	var collect func(cmd simplecobra.Commander)
+	collect = func(cmd simplecobra.Commander) {
+		cc := &cobra.Command{}
+		c.Assert(cmd.WithCobraCommand(cc), qt.IsNil)
+		for _, fs := range []*pflag.FlagSet{cc.Flags(), cc.PersistentFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				registered[f.Name] = true
+			})
+		}
+		for _, sub := range cmd.Commands() {
+			collect(sub)
+		}
+	}
+	collect(newRootCommand())
+
+	c.Assert(registered["baseURL"], qt.IsTrue)
+	for legacy, canonical := range helpers.LegacyHugoFlags() {
+		c.Assert(registered[canonical], qt.IsTrue, qt.Commentf("%s => %s", legacy, canonical))
+	}
+}
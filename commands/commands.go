@@ -19,7 +19,12 @@ import (
 
 // newExec wires up all of Hugo's CLI.
 func newExec() (*simplecobra.Exec, error) {
-	rootCmd := &rootCommand{
+	return simplecobra.New(newRootCommand())
+}
+
+// newRootCommand creates the root command with all its sub commands.
+func newRootCommand() *rootCommand {
+	return &rootCommand{
 		commands: []simplecobra.Commander{
 			newVersionCmd(),
 			newEnvCommand(),
@@ -35,7 +40,4 @@ func newExec() (*simplecobra.Exec, error) {
 			newReleaseCommand(),
 		},
 	}
-
-	return simplecobra.New(rootCmd)
-
 }
@@ -454,6 +454,11 @@ type RootConfig struct {
 	// Removes non-spacing marks from composite characters in content paths.
 	RemovePathAccents bool
 
+	// Enable this to also treat invisible separators such as the zero width
+	// space and the byte order mark as whitespace when creating paths and
+	// trimming summaries.
+	TrimUnicodeWhitespace bool
+
 	// Whether to track and print unused templates during the build.
 	PrintUnusedTemplates bool
 
@@ -484,7 +489,8 @@ type RootConfig struct {
 	TimeZone string
 
 	// Set titleCaseStyle to specify the title style used by the title template function and the automatic section titles in Hugo.
-	// It defaults to AP Stylebook for title casing, but you can also set it to Chicago or Go (every word starts with a capital letter).
+	// It defaults to AP Stylebook for title casing, but you can also set it to Chicago, Go (every word starts with a capital letter),
+	// Sentence (only the first letter is upper case) or Lower (all lower case).
 	TitleCaseStyle string
 
 	// The editor used for opening up new content.
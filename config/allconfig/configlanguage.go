@@ -159,6 +159,10 @@ func (c ConfigLanguage) RemovePathAccents() bool {
 	return c.config.RemovePathAccents
 }
 
+func (c ConfigLanguage) TrimUnicodeWhitespace() bool {
+	return c.config.TrimUnicodeWhitespace
+}
+
 func (c ConfigLanguage) DefaultContentLanguage() string {
 	return c.config.DefaultContentLanguage
 }
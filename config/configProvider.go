@@ -42,6 +42,7 @@ type AllProvider interface {
 	CanonifyURLs() bool
 	DisablePathToLower() bool
 	RemovePathAccents() bool
+	TrimUnicodeWhitespace() bool
 	IsUglyURLs(section string) bool
 	DefaultContentLanguage() string
 	DefaultContentLanguageInSubdir() bool
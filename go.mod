@@ -39,6 +39,7 @@ require (
 	github.com/kylelemons/godebug v1.1.0
 	github.com/kyokomi/emoji/v2 v2.2.11
 	github.com/magefile/mage v1.14.0
+	github.com/makiuchi-d/gozxing v0.1.1
 	github.com/marekm4/color-extractor v1.2.0
 	github.com/mattn/go-isatty v0.0.17
 	github.com/mitchellh/hashstructure v1.1.0
@@ -50,6 +51,7 @@ require (
 	github.com/rogpeppe/go-internal v1.10.1-0.20230508101108-a4f6fabd84c5
 	github.com/rwcarlsen/goexif v0.0.0-20190401172101-9e8deecbddbd
 	github.com/sanity-io/litter v1.5.5
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/afero v1.9.3
 	github.com/spf13/cast v1.5.1
 	github.com/spf13/cobra v1.7.0
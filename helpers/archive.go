@@ -0,0 +1,129 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// archiveModTime is the modification time set on all archive entries.
+// Zip can't represent times before 1980.
+var archiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// CreateArchive writes the files below root in fs to w as an archive in the
+// given format, one of "zip", "tar" or "tar.gz".
+// The entries are written in StableFileList order with fixed timestamps,
+// owners and permissions, so the same tree always gives the same bytes.
+func CreateArchive(fs afero.Fs, root string, w io.Writer, format string) error {
+	filenames, err := StableFileList(fs, root)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "zip":
+		return createZip(fs, root, filenames, w)
+	case "tar":
+		return createTar(fs, root, filenames, w)
+	case "tar.gz":
+		gw := gzip.NewWriter(w)
+		if err := createTar(fs, root, filenames, gw); err != nil {
+			return err
+		}
+		return gw.Close()
+	default:
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+func createZip(fs afero.Fs, root string, filenames []string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, filename := range filenames {
+		err := copyArchiveFile(fs, root, filename, func(fi os.FileInfo) (io.Writer, error) {
+			h := &zip.FileHeader{
+				Name:     filename,
+				Method:   zip.Deflate,
+				Modified: archiveModTime,
+			}
+			h.SetMode(archiveFileMode(fi))
+			return zw.CreateHeader(h)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func createTar(fs afero.Fs, root string, filenames []string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, filename := range filenames {
+		err := copyArchiveFile(fs, root, filename, func(fi os.FileInfo) (io.Writer, error) {
+			h := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     filename,
+				Size:     fi.Size(),
+				Mode:     int64(archiveFileMode(fi)),
+				ModTime:  archiveModTime,
+				Format:   tar.FormatPAX,
+			}
+			if err := tw.WriteHeader(h); err != nil {
+				return nil, err
+			}
+			return tw, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func copyArchiveFile(fs afero.Fs, root, filename string, create func(fi os.FileInfo) (io.Writer, error)) error {
+	f, err := fs.Open(filepath.Join(root, filepath.FromSlash(filename)))
+	if err != nil {
+		return fmt.Errorf("failed to archive %q: %w", filename, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to archive %q: %w", filename, err)
+	}
+	aw, err := create(fi)
+	if err != nil {
+		return fmt.Errorf("failed to archive %q: %w", filename, err)
+	}
+	if _, err := io.Copy(aw, f); err != nil {
+		return fmt.Errorf("failed to archive %q: %w", filename, err)
+	}
+	return nil
+}
+
+// archiveFileMode normalizes the file mode to 0755 for executables and 0644
+// for everything else.
+func archiveFileMode(fi os.FileInfo) os.FileMode {
+	if fi.Mode().Perm()&0o111 != 0 {
+		return 0o755
+	}
+	return 0o644
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+func TestCreateArchive(t *testing.T) {
+	c := qt.New(t)
+
+	files := []struct {
+		name    string
+		content string
+		mode    os.FileMode
+	}{
+		{"index.html", "<html>home</html>", 0o600},
+		{"posts/a/index.html", "<html>a</html>", 0o644},
+		{"css/styles.css", "body { color: red; }", 0o664},
+		{"bin/run.sh", "#!/bin/sh", 0o700},
+		{"empty.txt", "", 0o644},
+	}
+
+	newFs := func(reverse bool, modTime time.Time) afero.Fs {
+		fs := afero.NewMemMapFs()
+		for i := range files {
+			f := files[i]
+			if reverse {
+				f = files[len(files)-1-i]
+			}
+			filename := filepath.Join("public", filepath.FromSlash(f.name))
+			c.Assert(afero.WriteFile(fs, filename, []byte(f.content), 0o644), qt.IsNil)
+			c.Assert(fs.Chmod(filename, f.mode), qt.IsNil)
+			c.Assert(fs.Chtimes(filename, modTime, modTime), qt.IsNil)
+		}
+		return fs
+	}
+
+	fs1 := newFs(false, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))
+	fs2 := newFs(true, time.Now())
+
+	for _, format := range []string{"zip", "tar", "tar.gz"} {
+		c.Run(format, func(c *qt.C) {
+			var b1, b2 bytes.Buffer
+			c.Assert(helpers.CreateArchive(fs1, "public", &b1, format), qt.IsNil)
+			c.Assert(helpers.CreateArchive(fs2, "public", &b2, format), qt.IsNil)
+			c.Assert(b1.Len() > 0, qt.IsTrue)
+			c.Assert(bytes.Equal(b1.Bytes(), b2.Bytes()), qt.IsTrue)
+		})
+	}
+
+	c.Run("Content", func(c *qt.C) {
+		var b bytes.Buffer
+		c.Assert(helpers.CreateArchive(fs1, "public", &b, "tar.gz"), qt.IsNil)
+		gr, err := gzip.NewReader(&b)
+		c.Assert(err, qt.IsNil)
+		tr := tar.NewReader(gr)
+		var names []string
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			c.Assert(err, qt.IsNil)
+			names = append(names, h.Name)
+			if h.Name == "bin/run.sh" {
+				c.Assert(h.Mode, qt.Equals, int64(0o755))
+				content, err := io.ReadAll(tr)
+				c.Assert(err, qt.IsNil)
+				c.Assert(string(content), qt.Equals, "#!/bin/sh")
+			} else {
+				c.Assert(h.Mode, qt.Equals, int64(0o644))
+			}
+		}
+		c.Assert(names, qt.DeepEquals, []string{"bin/run.sh", "css/styles.css", "empty.txt", "index.html", "posts/a/index.html"})
+
+		b.Reset()
+		c.Assert(helpers.CreateArchive(fs1, "public", &b, "zip"), qt.IsNil)
+		zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		c.Assert(err, qt.IsNil)
+		c.Assert(zr.File, qt.HasLen, len(files))
+		c.Assert(zr.File[0].Name, qt.Equals, "bin/run.sh")
+		rc, err := zr.File[3].Open()
+		c.Assert(err, qt.IsNil)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(content), qt.Equals, "<html>home</html>")
+	})
+
+	c.Run("Invalid format", func(c *qt.C) {
+		c.Assert(helpers.CreateArchive(fs1, "public", io.Discard, "rar"), qt.ErrorMatches, `unsupported archive format "rar"`)
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The asset types used in AssetRef.
+const (
+	AssetTypeStylesheet = "stylesheet"
+	AssetTypeScript     = "script"
+	AssetTypeImage      = "image"
+	AssetTypeFont       = "font"
+	AssetTypeOther      = "other"
+)
+
+// AssetRef is an asset referenced from a HTML document.
+type AssetRef struct {
+	URL string
+	// One of the AssetType* constants.
+	Type string
+}
+
+var fontSuffixes = map[string]bool{
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+}
+
+// ExtractAssets returns the assets referenced from the HTML in b, in document
+// order and without duplicates. These are:
+//
+// - <link> elements with a rel of stylesheet, icon, apple-touch-icon,
+// preload, prefetch or modulepreload, classified by rel, the as attribute or
+// the URL's file extension.
+// - <script src>.
+// - <img> src and srcset, and <video poster>.
+// - <source> src and srcset, which are images inside <picture> and
+// AssetTypeOther inside <video> and <audio>.
+//
+// Links to other documents, e.g. <a href> or <link rel="canonical">, are not assets.
+func ExtractAssets(b []byte) []AssetRef {
+	var assets []AssetRef
+	seen := make(map[string]bool)
+	add := func(u, typ string) {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[u] {
+			return
+		}
+		seen[u] = true
+		assets = append(assets, AssetRef{URL: u, Type: typ})
+	}
+
+	var inPicture bool
+	z := html.NewTokenizer(bytes.NewReader(b))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return assets
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "picture" {
+				inPicture = false
+			}
+			continue
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			continue
+		}
+
+		tok := z.Token()
+		attr := func(key string) string {
+			for _, a := range tok.Attr {
+				if a.Key == key {
+					return a.Val
+				}
+			}
+			return ""
+		}
+
+		switch tok.Data {
+		case "picture":
+			inPicture = tt == html.StartTagToken
+		case "link":
+			href := attr("href")
+			if typ, ok := linkAssetType(attr("rel"), attr("as"), href); ok {
+				add(href, typ)
+			}
+		case "script":
+			add(attr("src"), AssetTypeScript)
+		case "img":
+			add(attr("src"), AssetTypeImage)
+			for _, u := range parseSrcset(attr("srcset")) {
+				add(u, AssetTypeImage)
+			}
+		case "video":
+			add(attr("poster"), AssetTypeImage)
+		case "source":
+			typ := AssetTypeOther
+			if inPicture || strings.HasPrefix(attr("type"), "image/") {
+				typ = AssetTypeImage
+			}
+			add(attr("src"), typ)
+			for _, u := range parseSrcset(attr("srcset")) {
+				add(u, typ)
+			}
+		}
+	}
+}
+
+// linkAssetType returns the asset type of a <link> element, and false if
+// it does not reference an asset.
+func linkAssetType(rel, as, href string) (string, bool) {
+	var isAsset bool
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		switch r {
+		case "stylesheet":
+			return AssetTypeStylesheet, true
+		case "icon", "apple-touch-icon":
+			return AssetTypeImage, true
+		case "modulepreload":
+			return AssetTypeScript, true
+		case "preload", "prefetch":
+			isAsset = true
+		}
+	}
+	if !isAsset {
+		return "", false
+	}
+
+	switch strings.ToLower(as) {
+	case "style":
+		return AssetTypeStylesheet, true
+	case "script":
+		return AssetTypeScript, true
+	case "image":
+		return AssetTypeImage, true
+	case "font":
+		return AssetTypeFont, true
+	}
+
+	u := href
+	if i := strings.IndexAny(u, "?#"); i != -1 {
+		u = u[:i]
+	}
+	switch ext := strings.ToLower(path.Ext(u)); {
+	case ext == ".css":
+		return AssetTypeStylesheet, true
+	case ext == ".js" || ext == ".mjs":
+		return AssetTypeScript, true
+	case fontSuffixes[ext]:
+		return AssetTypeFont, true
+	}
+
+	return AssetTypeOther, true
+}
+
+var assetTypeToPreloadAs = map[string]string{
+	AssetTypeStylesheet: "style",
+	AssetTypeScript:     "script",
+	AssetTypeImage:      "image",
+	AssetTypeFont:       "font",
+}
+
+// ResourceHints returns <link> resource hints for assets, one per line.
+// The render blocking stylesheets and fonts are preloaded. With strategy
+// "all", the other assets are prefetched; with "critical-only" (the default
+// if empty), they are left out. An unknown strategy gives an empty string.
+// Fonts get the crossorigin attribute, which browsers require to use the
+// preloaded font, also for fonts served from the same origin.
+func ResourceHints(assets []AssetRef, strategy string) string {
+	var all bool
+	switch strategy {
+	case "", "critical-only":
+	case "all":
+		all = true
+	default:
+		return ""
+	}
+
+	var lines []string
+	for _, asset := range assets {
+		critical := asset.Type == AssetTypeStylesheet || asset.Type == AssetTypeFont
+		if !critical && !all {
+			continue
+		}
+
+		rel := "prefetch"
+		if critical {
+			rel = "preload"
+		}
+
+		link := fmt.Sprintf(`<link rel="%s" href="%s"`, rel, html.EscapeString(asset.URL))
+		if as, found := assetTypeToPreloadAs[asset.Type]; found {
+			link += fmt.Sprintf(` as="%s"`, as)
+		}
+		if asset.Type == AssetTypeFont {
+			link += " crossorigin"
+		}
+		lines = append(lines, link+">")
+	}
+
+	return strings.Join(lines, "\n")
+}
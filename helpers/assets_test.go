@@ -0,0 +1,101 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestExtractAssets(t *testing.T) {
+	c := qt.New(t)
+
+	assets := helpers.ExtractAssets([]byte(`<!DOCTYPE html>
+<html>
+<head>
+  <link rel="stylesheet" href="/css/main.css">
+  <link rel="canonical" href="https://example.org/">
+  <link rel="alternate" type="application/rss+xml" href="/index.xml">
+  <link rel="icon" href="/favicon.ico">
+  <link rel="preload" href="/fonts/inter.woff2" as="font" type="font/woff2" crossorigin>
+  <link rel="prefetch" href="https://fonts.example.com/roboto.woff?v=2">
+  <link rel="preload" href="/data.json" as="fetch">
+  <script src="/js/app.js" defer></script>
+  <script>console.log("inline");</script>
+</head>
+<body>
+  <a href="/about/">About</a>
+  <img src="/images/a.jpg" srcset="/images/a-480.jpg 480w, /images/a-800.jpg 800w">
+  <img src="/images/a.jpg">
+  <picture>
+    <source srcset="/images/b.avif" type="image/avif">
+    <img src="/images/b.jpg">
+  </picture>
+  <video poster="/images/poster.jpg">
+    <source src="/video/intro.mp4" type="video/mp4">
+  </video>
+</body>
+</html>`))
+
+	c.Assert(assets, qt.DeepEquals, []helpers.AssetRef{
+		{URL: "/css/main.css", Type: helpers.AssetTypeStylesheet},
+		{URL: "/favicon.ico", Type: helpers.AssetTypeImage},
+		{URL: "/fonts/inter.woff2", Type: helpers.AssetTypeFont},
+		{URL: "https://fonts.example.com/roboto.woff?v=2", Type: helpers.AssetTypeFont},
+		{URL: "/data.json", Type: helpers.AssetTypeOther},
+		{URL: "/js/app.js", Type: helpers.AssetTypeScript},
+		{URL: "/images/a.jpg", Type: helpers.AssetTypeImage},
+		{URL: "/images/a-480.jpg", Type: helpers.AssetTypeImage},
+		{URL: "/images/a-800.jpg", Type: helpers.AssetTypeImage},
+		{URL: "/images/b.avif", Type: helpers.AssetTypeImage},
+		{URL: "/images/b.jpg", Type: helpers.AssetTypeImage},
+		{URL: "/images/poster.jpg", Type: helpers.AssetTypeImage},
+		{URL: "/video/intro.mp4", Type: helpers.AssetTypeOther},
+	})
+
+	c.Assert(helpers.ExtractAssets([]byte(`<html><body><p>No assets, <a href="/a/">just a link</a>.</p></body></html>`)), qt.HasLen, 0)
+	c.Assert(helpers.ExtractAssets(nil), qt.HasLen, 0)
+}
+
+func TestResourceHints(t *testing.T) {
+	c := qt.New(t)
+
+	assets := []helpers.AssetRef{
+		{URL: "/css/main.css", Type: helpers.AssetTypeStylesheet},
+		{URL: "https://fonts.example.com/inter.woff2", Type: helpers.AssetTypeFont},
+		{URL: "/js/app.js?a=1&b=2", Type: helpers.AssetTypeScript},
+		{URL: "/images/hero.jpg", Type: helpers.AssetTypeImage},
+		{URL: "/video/intro.mp4", Type: helpers.AssetTypeOther},
+	}
+
+	critical := `<link rel="preload" href="/css/main.css" as="style">
+<link rel="preload" href="https://fonts.example.com/inter.woff2" as="font" crossorigin>`
+
+	c.Assert(helpers.ResourceHints(assets, "critical-only"), qt.Equals, critical)
+	c.Assert(helpers.ResourceHints(assets, ""), qt.Equals, critical)
+	c.Assert(helpers.ResourceHints(assets, "all"), qt.Equals, critical+`
+<link rel="prefetch" href="/js/app.js?a=1&amp;b=2" as="script">
+<link rel="prefetch" href="/images/hero.jpg" as="image">
+<link rel="prefetch" href="/video/intro.mp4">`)
+
+	c.Assert(helpers.ResourceHints(assets, "none"), qt.Equals, "")
+	c.Assert(helpers.ResourceHints(nil, "all"), qt.Equals, "")
+
+	// Round trip.
+	page := []byte(`<link rel="stylesheet" href="/main.css"><img src="/a.png">`)
+	c.Assert(helpers.ResourceHints(helpers.ExtractAssets(page), "all"), qt.Equals, `<link rel="preload" href="/main.css" as="style">
+<link rel="prefetch" href="/a.png" as="image">`)
+}
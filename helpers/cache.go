@@ -0,0 +1,386 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bep/clock"
+	"github.com/gohugoio/hugo/common/htime"
+	"github.com/spf13/afero"
+)
+
+// CacheStats counts the hits and misses of a cache.
+// It's safe for concurrent use, and the zero value is ready to use.
+type CacheStats struct {
+	hits   uint64
+	misses uint64
+}
+
+// Hit records a cache hit.
+func (s *CacheStats) Hit() {
+	atomic.AddUint64(&s.hits, 1)
+}
+
+// Miss records a cache miss.
+func (s *CacheStats) Miss() {
+	atomic.AddUint64(&s.misses, 1)
+}
+
+// Report returns the number of hits and misses recorded and the hit ratio,
+// which is 0 if there have been no lookups.
+func (s *CacheStats) Report() (hits, misses int, ratio float64) {
+	hits = int(atomic.LoadUint64(&s.hits))
+	misses = int(atomic.LoadUint64(&s.misses))
+	if total := hits + misses; total > 0 {
+		ratio = float64(hits) / float64(total)
+	}
+	return
+}
+
+// LRUCacheOptions configures an LRUCache.
+type LRUCacheOptions[V any] struct {
+	// The maximum number of entries, 0 means no limit.
+	MaxEntries int
+
+	// The maximum total size of the entries as measured by Size,
+	// 0 means no limit.
+	MaxSize int64
+
+	// Size returns the size of a value, e.g. its length in bytes.
+	// Required if MaxSize is set.
+	Size func(v V) int64
+}
+
+// LRUCache is a cache bounded by number of entries and/or total size that
+// evicts the least recently used entries first.
+// It's safe for concurrent use.
+type LRUCache[K comparable, V any] struct {
+	opts LRUCacheOptions[V]
+
+	mu      sync.Mutex
+	size    int64
+	entries map[K]*list.Element
+	order   *list.List // Most recently used first.
+
+	stats CacheStats
+}
+
+type lruEntry[K comparable, V any] struct {
+	key  K
+	v    V
+	size int64
+}
+
+// NewLRUCache creates a new LRUCache with the given options.
+func NewLRUCache[K comparable, V any](opts LRUCacheOptions[V]) *LRUCache[K, V] {
+	if opts.MaxSize > 0 && opts.Size == nil {
+		panic("LRUCache: Size must be set when MaxSize is set")
+	}
+	return &LRUCache[K, V]{
+		opts:    opts,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the value for key and whether it was found, marking it as
+// recently used.
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[key]
+	if !found {
+		c.stats.Miss()
+		var v V
+		return v, false
+	}
+	c.stats.Hit()
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry[K, V]).v, true
+}
+
+// Add adds or replaces the value for key, marking it as recently used, and
+// evicts the least recently used entries if the cache is over its limits.
+// A value larger than MaxSize is evicted right away.
+func (c *LRUCache[K, V]) Add(key K, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var size int64
+	if c.opts.Size != nil {
+		size = c.opts.Size(v)
+	}
+
+	if el, found := c.entries[key]; found {
+		e := el.Value.(*lruEntry[K, V])
+		c.size += size - e.size
+		e.v, e.size = v, size
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, v: v, size: size})
+		c.size += size
+	}
+
+	for c.order.Len() > 0 &&
+		((c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries) ||
+			(c.opts.MaxSize > 0 && c.size > c.opts.MaxSize)) {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Remove removes the value for key, returning whether it was found.
+func (c *LRUCache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[key]
+	if found {
+		c.removeElement(el)
+	}
+	return found
+}
+
+func (c *LRUCache[K, V]) removeElement(el *list.Element) {
+	e := c.order.Remove(el).(*lruEntry[K, V])
+	delete(c.entries, e.key)
+	c.size -= e.size
+}
+
+// Len returns the number of entries.
+func (c *LRUCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Size returns the total size of the entries.
+func (c *LRUCache[K, V]) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Stats returns the hit and miss counts of Get.
+func (c *LRUCache[K, V]) Stats() *CacheStats {
+	return &c.stats
+}
+
+// TTLCache is a cache where each entry expires after its own time to live.
+// Expired entries are removed when looked up or by Prune.
+// It's safe for concurrent use.
+type TTLCache[K comparable, V any] struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[K]ttlEntry[V]
+}
+
+type ttlEntry[V any] struct {
+	v       V
+	expires time.Time // Zero means never.
+}
+
+// NewTTLCache creates a new TTLCache using the given clock to tell the time.
+// A nil clock means htime.Clock.
+func NewTTLCache[K comparable, V any](clock clock.Clock) *TTLCache[K, V] {
+	return &TTLCache[K, V]{clock: clock, entries: make(map[K]ttlEntry[V])}
+}
+
+func (c *TTLCache[K, V]) now() time.Time {
+	if c.clock == nil {
+		return htime.Now()
+	}
+	return c.clock.Now()
+}
+
+// Get returns the value for key and true if found and not expired.
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, found := c.entries[key]
+	if !found {
+		var v V
+		return v, false
+	}
+	if e.expired(c.now()) {
+		delete(c.entries, key)
+		var v V
+		return v, false
+	}
+	return e.v, true
+}
+
+// Set sets the value for key, expiring after ttl.
+// A ttl <= 0 means the value never expires.
+func (c *TTLCache[K, V]) Set(key K, v V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := ttlEntry[V]{v: v}
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+	}
+	c.entries[key] = e
+}
+
+// Delete removes the value for key.
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Prune removes all expired entries and returns how many were removed.
+func (c *TTLCache[K, V]) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	var n int
+	for k, e := range c.entries {
+		if e.expired(now) {
+			delete(c.entries, k)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of entries, including any expired entries not yet
+// removed.
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (e ttlEntry[V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+const (
+	// cacheFileMagic starts every file written by SaveCache.
+	cacheFileMagic = "HUGOCACHE"
+
+	// cacheFileVersion must be incremented when the file format changes.
+	cacheFileVersion = 1
+)
+
+// SaveCache writes entries to the file path in fs, to be read by LoadCache,
+// creating any missing directories.
+//
+// The file starts with a format version and a SHA-256 checksum of the
+// entries, which are written sorted by key, so the output is stable.
+func SaveCache(fs afero.Fs, path string, entries map[string][]byte) error {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var body bytes.Buffer
+	writeBytes := func(b []byte) {
+		var lenBuf [binary.MaxVarintLen64]byte
+		body.Write(lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(b)))])
+		body.Write(b)
+	}
+	for _, k := range keys {
+		writeBytes([]byte(k))
+		writeBytes(entries[k])
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	var buf bytes.Buffer
+	buf.WriteString(cacheFileMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(cacheFileVersion))
+	buf.Write(sum[:])
+	buf.Write(body.Bytes())
+
+	if err := fs.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, path, buf.Bytes(), 0666)
+}
+
+// LoadCache reads the entries written by SaveCache to the file path in fs.
+// A missing file, a file written with another version of the format and a
+// corrupt file all load as an empty cache without an error.
+func LoadCache(fs afero.Fs, path string) (map[string][]byte, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string][]byte{}, nil
+		}
+		return nil, err
+	}
+
+	entries, ok := decodeCacheFile(b)
+	if !ok {
+		return map[string][]byte{}, nil
+	}
+	return entries, nil
+}
+
+func decodeCacheFile(b []byte) (map[string][]byte, bool) {
+	headerLen := len(cacheFileMagic) + 4 + sha256.Size
+	if len(b) < headerLen || string(b[:len(cacheFileMagic)]) != cacheFileMagic {
+		return nil, false
+	}
+	b = b[len(cacheFileMagic):]
+	if binary.BigEndian.Uint32(b) != cacheFileVersion {
+		return nil, false
+	}
+	b = b[4:]
+	sum, body := b[:sha256.Size], b[sha256.Size:]
+	if actual := sha256.Sum256(body); !bytes.Equal(actual[:], sum) {
+		return nil, false
+	}
+
+	readBytes := func() ([]byte, bool) {
+		n, size := binary.Uvarint(body)
+		if size <= 0 || uint64(len(body)-size) < n {
+			return nil, false
+		}
+		v := body[size : size+int(n)]
+		body = body[size+int(n):]
+		return v, true
+	}
+
+	entries := make(map[string][]byte)
+	for len(body) > 0 {
+		k, ok := readBytes()
+		if !ok {
+			return nil, false
+		}
+		v, ok := readBytes()
+		if !ok {
+			return nil, false
+		}
+		entries[string(k)] = append([]byte{}, v...)
+	}
+	return entries, true
+}
@@ -0,0 +1,310 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+func TestCacheStats(t *testing.T) {
+	c := qt.New(t)
+
+	var s helpers.CacheStats
+	hits, misses, ratio := s.Report()
+	c.Assert(hits, qt.Equals, 0)
+	c.Assert(misses, qt.Equals, 0)
+	c.Assert(ratio, qt.Equals, 0.0)
+
+	s.Miss()
+	hits, misses, ratio = s.Report()
+	c.Assert(hits, qt.Equals, 0)
+	c.Assert(misses, qt.Equals, 1)
+	c.Assert(ratio, qt.Equals, 0.0)
+
+	s.Hit()
+	s.Hit()
+	s.Hit()
+	hits, misses, ratio = s.Report()
+	c.Assert(hits, qt.Equals, 3)
+	c.Assert(misses, qt.Equals, 1)
+	c.Assert(ratio, qt.Equals, 0.75)
+}
+
+func TestCacheStatsConcurrent(t *testing.T) {
+	c := qt.New(t)
+
+	var s helpers.CacheStats
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Hit()
+				if j%4 == 0 {
+					s.Miss()
+				}
+				s.Report()
+			}
+		}()
+	}
+	wg.Wait()
+
+	hits, misses, ratio := s.Report()
+	c.Assert(hits, qt.Equals, 1000)
+	c.Assert(misses, qt.Equals, 250)
+	c.Assert(ratio, qt.Equals, 0.8)
+}
+
+func TestLRUCacheMaxEntries(t *testing.T) {
+	c := qt.New(t)
+
+	cache := helpers.NewLRUCache[string, int](helpers.LRUCacheOptions[int]{MaxEntries: 3})
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	cache.Add("c", 3)
+	cache.Add("d", 4)
+	c.Assert(cache.Len(), qt.Equals, 3)
+
+	_, found := cache.Get("a")
+	c.Assert(found, qt.IsFalse)
+	v, found := cache.Get("b")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(v, qt.Equals, 2)
+
+	// b was just used, so c is the least recently used.
+	cache.Add("e", 5)
+	_, found = cache.Get("c")
+	c.Assert(found, qt.IsFalse)
+	for _, k := range []string{"b", "d", "e"} {
+		_, found = cache.Get(k)
+		c.Assert(found, qt.IsTrue, qt.Commentf(k))
+	}
+
+	// Replacing a value marks it as recently used.
+	cache.Add("b", 22)
+	cache.Add("f", 6)
+	_, found = cache.Get("d")
+	c.Assert(found, qt.IsFalse)
+	v, _ = cache.Get("b")
+	c.Assert(v, qt.Equals, 22)
+
+	c.Assert(cache.Remove("b"), qt.IsTrue)
+	c.Assert(cache.Remove("b"), qt.IsFalse)
+	c.Assert(cache.Len(), qt.Equals, 2)
+
+	hits, misses, _ := cache.Stats().Report()
+	c.Assert(hits, qt.Equals, 5)
+	c.Assert(misses, qt.Equals, 3)
+}
+
+func TestLRUCacheMaxSize(t *testing.T) {
+	c := qt.New(t)
+
+	cache := helpers.NewLRUCache[string, []byte](helpers.LRUCacheOptions[[]byte]{
+		MaxSize: 10,
+		Size: func(v []byte) int64 {
+			return int64(len(v))
+		},
+	})
+
+	cache.Add("a", make([]byte, 4))
+	cache.Add("b", make([]byte, 4))
+	c.Assert(cache.Size(), qt.Equals, int64(8))
+	_, found := cache.Get("a")
+	c.Assert(found, qt.IsTrue)
+
+	// Evicts b, the least recently used.
+	cache.Add("c", make([]byte, 4))
+	c.Assert(cache.Size(), qt.Equals, int64(8))
+	_, found = cache.Get("b")
+	c.Assert(found, qt.IsFalse)
+
+	// Growing a value evicts others.
+	cache.Add("c", make([]byte, 9))
+	c.Assert(cache.Len(), qt.Equals, 1)
+	c.Assert(cache.Size(), qt.Equals, int64(9))
+
+	// Too large to be cached at all.
+	cache.Add("d", make([]byte, 11))
+	c.Assert(cache.Len(), qt.Equals, 0)
+	c.Assert(cache.Size(), qt.Equals, int64(0))
+
+	c.Assert(func() {
+		helpers.NewLRUCache[string, int](helpers.LRUCacheOptions[int]{MaxSize: 10})
+	}, qt.PanicMatches, "LRUCache: Size must be set when MaxSize is set")
+}
+
+func TestLRUCacheConcurrent(t *testing.T) {
+	c := qt.New(t)
+
+	cache := helpers.NewLRUCache[int, int](helpers.LRUCacheOptions[int]{MaxEntries: 50})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				cache.Add(i*1000+j, j)
+				cache.Get(i*1000 + j/2)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	c.Assert(cache.Len(), qt.Equals, 50)
+}
+
+// fakeClock is a clock.Clock that only moves when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+func (c *fakeClock) Until(t time.Time) time.Duration { return t.Sub(c.Now()) }
+func (c *fakeClock) Offset() time.Duration           { return c.Now().Sub(time.Now()) }
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestTTLCache(t *testing.T) {
+	c := qt.New(t)
+
+	clock := &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache := helpers.NewTTLCache[string, string](clock)
+
+	cache.Set("a", "A", time.Minute)
+	cache.Set("b", "B", time.Hour)
+	cache.Set("c", "C", 0)
+
+	v, found := cache.Get("a")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(v, qt.Equals, "A")
+
+	clock.Advance(59 * time.Second)
+	_, found = cache.Get("a")
+	c.Assert(found, qt.IsTrue)
+
+	// Expires exactly at the TTL.
+	clock.Advance(time.Second)
+	v, found = cache.Get("a")
+	c.Assert(found, qt.IsFalse)
+	c.Assert(v, qt.Equals, "")
+	c.Assert(cache.Len(), qt.Equals, 2)
+
+	// Setting again refreshes the TTL.
+	cache.Set("a", "A2", time.Minute)
+	clock.Advance(30 * time.Second)
+	v, found = cache.Get("a")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(v, qt.Equals, "A2")
+
+	clock.Advance(2 * time.Hour)
+	c.Assert(cache.Len(), qt.Equals, 3)
+	c.Assert(cache.Prune(), qt.Equals, 2)
+	c.Assert(cache.Len(), qt.Equals, 1)
+
+	// No TTL never expires.
+	v, found = cache.Get("c")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(v, qt.Equals, "C")
+
+	cache.Delete("c")
+	_, found = cache.Get("c")
+	c.Assert(found, qt.IsFalse)
+}
+
+func TestTTLCacheSystemClock(t *testing.T) {
+	c := qt.New(t)
+
+	cache := helpers.NewTTLCache[int, int](nil)
+	cache.Set(1, 10, time.Hour)
+	cache.Set(2, 20, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	v, found := cache.Get(1)
+	c.Assert(found, qt.IsTrue)
+	c.Assert(v, qt.Equals, 10)
+	_, found = cache.Get(2)
+	c.Assert(found, qt.IsFalse)
+}
+
+func TestSaveAndLoadCache(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	entries := map[string][]byte{
+		"a":                 []byte("A"),
+		"images/sunset.jpg": bytes.Repeat([]byte{0, 1, 2, 255}, 1000),
+		"empty":             {},
+		"":                  []byte("no key"),
+	}
+
+	c.Assert(helpers.SaveCache(fs, "cache/build/entries.cache", entries), qt.IsNil)
+	loaded, err := helpers.LoadCache(fs, "cache/build/entries.cache")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loaded, qt.HasLen, len(entries))
+	for k, v := range entries {
+		c.Assert(loaded[k], qt.DeepEquals, v, qt.Commentf(k))
+	}
+
+	// Stable output.
+	b1, _ := afero.ReadFile(fs, "cache/build/entries.cache")
+	c.Assert(helpers.SaveCache(fs, "cache/build/entries2.cache", entries), qt.IsNil)
+	b2, _ := afero.ReadFile(fs, "cache/build/entries2.cache")
+	c.Assert(b2, qt.DeepEquals, b1)
+
+	// Missing.
+	loaded, err = helpers.LoadCache(fs, "cache/missing.cache")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loaded, qt.HasLen, 0)
+
+	assertLoadsEmpty := func(b []byte) {
+		c.Helper()
+		c.Assert(afero.WriteFile(fs, "cache/corrupt.cache", b, 0666), qt.IsNil)
+		loaded, err := helpers.LoadCache(fs, "cache/corrupt.cache")
+		c.Assert(err, qt.IsNil)
+		c.Assert(loaded, qt.HasLen, 0)
+	}
+
+	// Corrupt.
+	corrupt := append([]byte(nil), b1...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	assertLoadsEmpty(corrupt)
+	assertLoadsEmpty(b1[:len(b1)-10])
+	assertLoadsEmpty([]byte("not a cache"))
+	assertLoadsEmpty(nil)
+
+	// Another version.
+	otherVersion := append([]byte(nil), b1...)
+	otherVersion[len("HUGOCACHE")+3]++
+	assertLoadsEmpty(otherVersion)
+}
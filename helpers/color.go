@@ -0,0 +1,57 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// ParseColor parses a CSS color given as a hex color code, with or without
+// the leading #, in any of the forms rgb, rgba, rrggbb and rrggbbaa, or as
+// one of the SVG 1.1 named colors, e.g. "steelblue".
+func ParseColor(s string) (color.Color, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+
+	if c, found := colornames.Map[v]; found && !strings.HasPrefix(v, "#") {
+		return color.NRGBA(c), nil
+	}
+
+	v = strings.TrimPrefix(v, "#")
+	if len(v) == 3 || len(v) == 4 {
+		var expanded strings.Builder
+		for _, r := range v {
+			expanded.WriteRune(r)
+			expanded.WriteRune(r)
+		}
+		v = expanded.String()
+	}
+	if len(v) == 6 {
+		v += "ff"
+	}
+	if len(v) != 8 {
+		return nil, fmt.Errorf("invalid color: %q", s)
+	}
+
+	b, err := hex.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color: %q", s)
+	}
+
+	return color.NRGBA{b[0], b[1], b[2], b[3]}, nil
+}
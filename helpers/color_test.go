@@ -0,0 +1,53 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"image/color"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestParseColor(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect any
+	}{
+		{"#ff0000", color.NRGBA{255, 0, 0, 255}},
+		{"FF0000", color.NRGBA{255, 0, 0, 255}},
+		{"#f00", color.NRGBA{255, 0, 0, 255}},
+		{"#f008", color.NRGBA{255, 0, 0, 136}},
+		{"#11223380", color.NRGBA{17, 34, 51, 128}},
+		{" SteelBlue ", color.NRGBA{70, 130, 180, 255}},
+		{"white", color.NRGBA{255, 255, 255, 255}},
+		{"#white", false},
+		{"#ff00", color.NRGBA{255, 255, 0, 0}},
+		{"#ff000", false},
+		{"#gg0000", false},
+		{"", false},
+		{"notacolor", false},
+	} {
+		col, err := helpers.ParseColor(test.in)
+		if b, ok := test.expect.(bool); ok && !b {
+			c.Assert(err, qt.ErrorMatches, `invalid color: ".*"`, qt.Commentf("%q", test.in))
+			continue
+		}
+		c.Assert(err, qt.IsNil, qt.Commentf("%q", test.in))
+		c.Assert(col, qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+}
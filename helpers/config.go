@@ -0,0 +1,313 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/spf13/cast"
+)
+
+// ConfigChangeKind is the kind of a ConfigChange.
+type ConfigChangeKind string
+
+const (
+	ConfigChangeAdded   ConfigChangeKind = "added"
+	ConfigChangeRemoved ConfigChangeKind = "removed"
+	ConfigChangeChanged ConfigChangeKind = "changed"
+)
+
+// ConfigChange is a difference between two configurations, see ConfigDiff.
+type ConfigChange struct {
+	// The dot separated path to the key, e.g. "markup.goldmark.renderer.unsafe".
+	Path string
+	Kind ConfigChangeKind
+
+	// The value before and after; Old is nil for added keys and New is
+	// nil for removed keys.
+	Old any
+	New any
+}
+
+// ConfigDiff returns the differences between the configurations old and new,
+// sorted by path.
+// Nested maps are compared key by key, so changes are reported for the
+// leaf values, with the exception of empty maps. Other values, including
+// slices, are compared as a whole.
+// Keys are compared as is, so any normalization, e.g. lower casing, must be
+// done by the caller.
+func ConfigDiff(old, new map[string]any) []ConfigChange {
+	var changes []ConfigChange
+	diffConfigMaps("", old, new, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func diffConfigMaps(prefix string, old, new map[string]any, changes *[]ConfigChange) {
+	for k, ov := range old {
+		path := prefix + k
+		nv, found := new[k]
+		if !found {
+			addConfigLeaves(path, ov, ConfigChangeRemoved, changes)
+			continue
+		}
+		om, oIsMap := toConfigMap(ov)
+		nm, nIsMap := toConfigMap(nv)
+		switch {
+		case oIsMap && nIsMap && len(om) > 0 && len(nm) > 0:
+			diffConfigMaps(path+".", om, nm, changes)
+		case !reflect.DeepEqual(ov, nv):
+			*changes = append(*changes, ConfigChange{Path: path, Kind: ConfigChangeChanged, Old: ov, New: nv})
+		}
+	}
+	for k, nv := range new {
+		if _, found := old[k]; !found {
+			addConfigLeaves(prefix+k, nv, ConfigChangeAdded, changes)
+		}
+	}
+}
+
+// addConfigLeaves adds an added or removed change for each leaf value in v.
+func addConfigLeaves(path string, v any, kind ConfigChangeKind, changes *[]ConfigChange) {
+	if m, ok := toConfigMap(v); ok && len(m) > 0 {
+		for k, vv := range m {
+			addConfigLeaves(path+"."+k, vv, kind, changes)
+		}
+		return
+	}
+	change := ConfigChange{Path: path, Kind: kind}
+	if kind == ConfigChangeAdded {
+		change.New = v
+	} else {
+		change.Old = v
+	}
+	*changes = append(*changes, change)
+}
+
+func toConfigMap(v any) (map[string]any, bool) {
+	switch vv := v.(type) {
+	case map[string]any:
+		return vv, true
+	case maps.Params:
+		return vv, true
+	case map[any]any:
+		m := make(map[string]any, len(vv))
+		for k, v := range vv {
+			m[cast.ToString(k)] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// LintSeverity is the severity of a LintFinding.
+type LintSeverity string
+
+const (
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+)
+
+// LintFinding is a likely mistake in a configuration, see LintConfig.
+type LintFinding struct {
+	// The name of the rule that found it.
+	Rule string
+
+	Severity LintSeverity
+
+	// The config key the finding is about, if any.
+	Key string
+
+	Message string
+}
+
+// ConfigRule checks a configuration for a mistake, see RegisterConfigRule.
+type ConfigRule func(m map[string]any) []LintFinding
+
+type namedConfigRule struct {
+	name string
+	rule ConfigRule
+}
+
+var (
+	configRulesMu sync.RWMutex
+	configRules   []namedConfigRule
+)
+
+func init() {
+	RegisterConfigRule("baseurl-scheme", lintBaseURLScheme)
+	RegisterConfigRule("paginate-positive", lintPaginatePositive)
+	RegisterConfigRule("duplicate-keys", lintDuplicateKeys)
+}
+
+// RegisterConfigRule registers a rule to be run by LintConfig, replacing any
+// rule already registered with the same name. The Rule field of the findings
+// is set to name if empty.
+func RegisterConfigRule(name string, rule ConfigRule) {
+	configRulesMu.Lock()
+	defer configRulesMu.Unlock()
+
+	for i, r := range configRules {
+		if r.name == name {
+			configRules[i].rule = rule
+			return
+		}
+	}
+	configRules = append(configRules, namedConfigRule{name: name, rule: rule})
+}
+
+// LintConfig runs the registered rules on the configuration m and returns
+// their findings, in rule registration order.
+// Keys in m are looked up case-insensitively.
+// The built-in rules check for
+//
+//   - a baseURL without a scheme, e.g. "example.org/"
+//   - a paginate value that is not a positive number
+//   - top level keys only differing in case, e.g. "uglyURLs" and "uglyurls",
+//     of which only one will be used.
+func LintConfig(m map[string]any) []LintFinding {
+	configRulesMu.RLock()
+	rules := append([]namedConfigRule(nil), configRules...)
+	configRulesMu.RUnlock()
+
+	var findings []LintFinding
+	for _, r := range rules {
+		for _, f := range r.rule(m) {
+			if f.Rule == "" {
+				f.Rule = r.name
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func lintBaseURLScheme(m map[string]any) []LintFinding {
+	v, found := Params(m).Get("baseURL")
+	if !found {
+		return nil
+	}
+	s, ok := v.(string)
+	if !ok || s == "" || s == "/" {
+		return nil
+	}
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		return nil
+	}
+	return []LintFinding{{
+		Severity: LintWarning,
+		Key:      "baseURL",
+		Message:  fmt.Sprintf("baseURL %q has no scheme, did you mean %q?", s, "https://"+strings.TrimPrefix(s, "//")),
+	}}
+}
+
+func lintPaginatePositive(m map[string]any) []LintFinding {
+	v, found := Params(m).Get("paginate")
+	if !found {
+		return nil
+	}
+	if n, err := cast.ToIntE(v); err == nil && n > 0 {
+		return nil
+	}
+	return []LintFinding{{
+		Severity: LintError,
+		Key:      "paginate",
+		Message:  fmt.Sprintf("paginate must be a positive number, got %v", v),
+	}}
+}
+
+func lintDuplicateKeys(m map[string]any) []LintFinding {
+	byLower := make(map[string][]string)
+	for k := range m {
+		lower := strings.ToLower(k)
+		byLower[lower] = append(byLower[lower], k)
+	}
+
+	var findings []LintFinding
+	for _, keys := range byLower {
+		if len(keys) < 2 {
+			continue
+		}
+		sort.Strings(keys)
+		findings = append(findings, LintFinding{
+			Severity: LintError,
+			Key:      keys[0],
+			Message:  fmt.Sprintf("keys %s only differ in case and only one of them will be used", strings.Join(keys, ", ")),
+		})
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Key < findings[j].Key
+	})
+	return findings
+}
+
+// ExportConfig serializes the configuration m to format, one of "toml",
+// "yaml" or "json". The keys are sorted, so the output is stable.
+func ExportConfig(m map[string]any, format string) ([]byte, error) {
+	f := metadecoders.FormatFromString(format)
+	switch f {
+	case metadecoders.TOML, metadecoders.YAML, metadecoders.JSON:
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	if m == nil {
+		m = map[string]any{}
+	}
+
+	var buf bytes.Buffer
+	if err := parser.InterfaceToConfig(normalizeConfigValue(m), f, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeConfigValue returns v with any nested maps converted to
+// map[string]any, which all the encoders write with sorted keys.
+func normalizeConfigValue(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(vv))
+		for k, v := range vv {
+			m[k] = normalizeConfigValue(v)
+		}
+		return m
+	case maps.Params:
+		return normalizeConfigValue(map[string]any(vv))
+	case map[any]any:
+		m := make(map[string]any, len(vv))
+		for k, v := range vv {
+			m[cast.ToString(k)] = normalizeConfigValue(v)
+		}
+		return m
+	case []any:
+		s := make([]any, len(vv))
+		for i, v := range vv {
+			s[i] = normalizeConfigValue(v)
+		}
+		return s
+	}
+	return v
+}
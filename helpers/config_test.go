@@ -0,0 +1,191 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+)
+
+func TestConfigDiff(t *testing.T) {
+	c := qt.New(t)
+
+	old := map[string]any{
+		"baseurl":  "https://example.org/",
+		"paginate": 10,
+		"uglyurls": false,
+		"taxonomies": map[string]any{
+			"tag":      "tags",
+			"category": "categories",
+		},
+		"markup": map[string]any{
+			"goldmark": map[string]any{
+				"extensions": maps.Params{"typographer": true},
+			},
+		},
+		"outputs":  []string{"html"},
+		"security": map[string]any{},
+	}
+
+	new := map[string]any{
+		"baseurl":  "https://example.org/",
+		"paginate": 20,
+		"taxonomies": map[string]any{
+			"tag": "tags",
+		},
+		"markup": map[string]any{
+			"goldmark": maps.Params{
+				"extensions": maps.Params{"typographer": true},
+				"renderer": map[string]any{
+					"unsafe": true,
+				},
+			},
+		},
+		"outputs":  []string{"html", "rss"},
+		"security": map[string]any{"enableinlineshortcodes": false},
+		"timeout":  "30s",
+	}
+
+	c.Assert(helpers.ConfigDiff(old, new), qt.DeepEquals, []helpers.ConfigChange{
+		{Path: "markup.goldmark.renderer.unsafe", Kind: helpers.ConfigChangeAdded, New: true},
+		{Path: "outputs", Kind: helpers.ConfigChangeChanged, Old: []string{"html"}, New: []string{"html", "rss"}},
+		{Path: "paginate", Kind: helpers.ConfigChangeChanged, Old: 10, New: 20},
+		{Path: "security", Kind: helpers.ConfigChangeChanged, Old: map[string]any{}, New: map[string]any{"enableinlineshortcodes": false}},
+		{Path: "taxonomies.category", Kind: helpers.ConfigChangeRemoved, Old: "categories"},
+		{Path: "timeout", Kind: helpers.ConfigChangeAdded, New: "30s"},
+		{Path: "uglyurls", Kind: helpers.ConfigChangeRemoved, Old: false},
+	})
+
+	c.Assert(helpers.ConfigDiff(old, old), qt.HasLen, 0)
+	c.Assert(helpers.ConfigDiff(map[string]any{"a": map[any]any{"b": 1}}, map[string]any{"a": map[string]any{"b": 1}}), qt.HasLen, 0)
+	c.Assert(helpers.ConfigDiff(nil, map[string]any{"a": map[string]any{"b": 1, "c": 2}}), qt.DeepEquals, []helpers.ConfigChange{
+		{Path: "a.b", Kind: helpers.ConfigChangeAdded, New: 1},
+		{Path: "a.c", Kind: helpers.ConfigChangeAdded, New: 2},
+	})
+	c.Assert(helpers.ConfigDiff(map[string]any{"a": map[string]any{"b": 1}}, map[string]any{"a": "b"}), qt.DeepEquals, []helpers.ConfigChange{
+		{Path: "a", Kind: helpers.ConfigChangeChanged, Old: map[string]any{"b": 1}, New: "b"},
+	})
+}
+
+func TestLintConfig(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.LintConfig(map[string]any{
+		"baseURL":  "https://example.org/docs/",
+		"paginate": 10,
+		"uglyURLs": true,
+		"params":   map[string]any{"Foo": 1, "foo": 2},
+	}), qt.HasLen, 0)
+	c.Assert(helpers.LintConfig(map[string]any{"baseurl": "/"}), qt.HasLen, 0)
+	c.Assert(helpers.LintConfig(nil), qt.HasLen, 0)
+
+	c.Assert(helpers.LintConfig(map[string]any{
+		"baseurl":  "example.org/",
+		"paginate": 0,
+		"uglyURLs": true,
+		"uglyurls": false,
+	}), qt.DeepEquals, []helpers.LintFinding{
+		{Rule: "baseurl-scheme", Severity: helpers.LintWarning, Key: "baseURL", Message: `baseURL "example.org/" has no scheme, did you mean "https://example.org/"?`},
+		{Rule: "paginate-positive", Severity: helpers.LintError, Key: "paginate", Message: "paginate must be a positive number, got 0"},
+		{Rule: "duplicate-keys", Severity: helpers.LintError, Key: "uglyURLs", Message: "keys uglyURLs, uglyurls only differ in case and only one of them will be used"},
+	})
+
+	findings := helpers.LintConfig(map[string]any{"baseURL": "//example.org", "paginate": "ten"})
+	c.Assert(findings, qt.HasLen, 2)
+	c.Assert(findings[0].Message, qt.Equals, `baseURL "//example.org" has no scheme, did you mean "https://example.org"?`)
+	c.Assert(findings[1].Message, qt.Equals, "paginate must be a positive number, got ten")
+}
+
+func TestRegisterConfigRule(t *testing.T) {
+	c := qt.New(t)
+
+	helpers.RegisterConfigRule("test-theme", func(m map[string]any) []helpers.LintFinding {
+		if _, found := helpers.Params(m).Get("themesDir"); found {
+			return []helpers.LintFinding{{Severity: helpers.LintWarning, Key: "themesDir", Message: "themesDir is set"}}
+		}
+		return nil
+	})
+
+	c.Assert(helpers.LintConfig(map[string]any{"title": "Hugo"}), qt.HasLen, 0)
+	c.Assert(helpers.LintConfig(map[string]any{"themesdir": "themes"}), qt.DeepEquals, []helpers.LintFinding{
+		{Rule: "test-theme", Severity: helpers.LintWarning, Key: "themesDir", Message: "themesDir is set"},
+	})
+
+	// Replace the rule.
+	helpers.RegisterConfigRule("test-theme", func(m map[string]any) []helpers.LintFinding {
+		return nil
+	})
+	c.Assert(helpers.LintConfig(map[string]any{"themesdir": "themes"}), qt.HasLen, 0)
+}
+
+func TestExportConfig(t *testing.T) {
+	c := qt.New(t)
+
+	m := map[string]any{
+		"title":    "Hugo",
+		"baseurl":  "https://example.org/",
+		"uglyurls": true,
+		"params": maps.Params{
+			"author":   "Bep",
+			"tags":     []any{"a", "b"},
+			"nested":   map[any]any{"z": "last", "a": "first"},
+			"empty":    "",
+			"bestIdea": "static sites",
+		},
+		"languages": map[string]any{
+			"nn": map[string]any{"languagename": "Nynorsk"},
+			"en": map[string]any{"languagename": "English"},
+		},
+	}
+
+	for _, format := range []string{"toml", "yaml", "json"} {
+		c.Run(format, func(c *qt.C) {
+			b, err := helpers.ExportConfig(m, format)
+			c.Assert(err, qt.IsNil)
+
+			for i := 0; i < 10; i++ {
+				b2, err := helpers.ExportConfig(m, format)
+				c.Assert(err, qt.IsNil)
+				c.Assert(string(b2), qt.Equals, string(b))
+			}
+
+			decoded, err := metadecoders.Default.UnmarshalToMap(b, metadecoders.FormatFromString(format))
+			c.Assert(err, qt.IsNil)
+			c.Assert(decoded["title"], qt.Equals, "Hugo")
+			c.Assert(decoded["uglyurls"], qt.Equals, true)
+			nested, found := helpers.Params(decoded).Get("params.nested.z")
+			c.Assert(found, qt.IsTrue)
+			c.Assert(nested, qt.Equals, "last")
+			name, _ := helpers.Params(decoded).Get("languages.nn.languagename")
+			c.Assert(name, qt.Equals, "Nynorsk")
+			c.Assert(helpers.ConfigDiff(m, decoded), qt.HasLen, 0)
+
+			// Round trip.
+			b3, err := helpers.ExportConfig(decoded, format)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(b3), qt.Equals, string(b))
+		})
+	}
+
+	b, err := helpers.ExportConfig(map[string]any{"b": 1, "a": 2}, "yaml")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "a: 2\nb: 1\n")
+
+	_, err = helpers.ExportConfig(m, "xml")
+	c.Assert(err, qt.ErrorMatches, `unsupported config format "xml"`)
+}
@@ -244,6 +244,9 @@ func (c *ContentSpec) TruncateWordsToWholeSentence(s string) (string, bool) {
 		return s, false
 	}
 
+	if c.Cfg.TrimUnicodeWhitespace() {
+		return strings.TrimFunc(s[:endIndex], IsWhitespaceUnicode), endIndex < len(s)
+	}
 	return strings.TrimSpace(s[:endIndex]), endIndex < len(s)
 }
 
@@ -97,6 +97,25 @@ func TestTruncateWordsToWholeSentence(t *testing.T) {
 	}
 }
 
+func TestTruncateWordsToWholeSentenceTrimUnicodeWhitespace(t *testing.T) {
+	c := qt.New(t)
+
+	input := "\ufeff\u200bThis is a sentence.\u200b Another one."
+	cfg := config.New()
+	cfg.Set("summaryLength", 2)
+
+	spec := newTestContentSpec(cfg)
+	output, truncated := spec.TruncateWordsToWholeSentence(input)
+	c.Assert(output, qt.Equals, "\ufeff\u200bThis is a sentence.")
+	c.Assert(truncated, qt.IsTrue)
+
+	cfg.Set("trimUnicodeWhitespace", true)
+	spec = newTestContentSpec(cfg)
+	output, truncated = spec.TruncateWordsToWholeSentence(input)
+	c.Assert(output, qt.Equals, "This is a sentence.")
+	c.Assert(truncated, qt.IsTrue)
+}
+
 func TestTruncateWordsByRune(t *testing.T) {
 
 	type test struct {
@@ -0,0 +1,435 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/css"
+	"golang.org/x/net/html"
+)
+
+// cssRule is a top level or nested rule in a stylesheet.
+type cssRule struct {
+	// The selectors of a style rule, e.g. ["h1", ".title"] for "h1, .title { ... }".
+	selectors []string
+
+	// The source of the rule, including its leading whitespace.
+	source []byte
+
+	// Set for grouping at-rules, e.g. @media and @supports.
+	header   []byte
+	children []*cssRule
+	footer   []byte
+}
+
+func (r *cssRule) isGroup() bool {
+	return r.header != nil
+}
+
+// cssGroupingAtRules are the at-rules whose nested rules are filtered
+// individually. All other at-rules, e.g. @font-face and @keyframes, are kept as a whole.
+var cssGroupingAtRules = map[string]bool{
+	"@media": true, "@supports": true, "@document": true, "@layer": true, "@container": true,
+}
+
+// parseCSSRules parses the stylesheet b into its rules, keeping the original
+// source of every rule intact. Comments are dropped.
+func parseCSSRules(b []byte) ([]*cssRule, error) {
+	// The parser modifies its input, e.g. lower cases at-rule names, so give it
+	// a copy and slice the original.
+	p := css.NewParser(parse.NewInputBytes(append([]byte(nil), b...)), false)
+
+	root := &cssRule{}
+	stack := []*cssRule{root}
+	start := 0
+	var selectors []string
+
+	selector := func() string {
+		var sb strings.Builder
+		for _, v := range p.Values() {
+			sb.Write(v.Data)
+		}
+		return strings.TrimSpace(sb.String())
+	}
+
+	// skipBlock skips to the end of the block just opened.
+	skipBlock := func() error {
+		for depth := 1; depth > 0; {
+			gt, _, _ := p.Next()
+			switch gt {
+			case css.ErrorGrammar:
+				return p.Err()
+			case css.BeginAtRuleGrammar, css.BeginRulesetGrammar:
+				depth++
+			case css.EndAtRuleGrammar, css.EndRulesetGrammar:
+				depth--
+			}
+		}
+		return nil
+	}
+
+	for {
+		gt, _, data := p.Next()
+		parent := stack[len(stack)-1]
+		switch gt {
+		case css.ErrorGrammar:
+			if err := p.Err(); err != io.EOF {
+				return nil, err
+			}
+			return root.children, nil
+		case css.CommentGrammar:
+			start = p.Offset()
+		case css.QualifiedRuleGrammar:
+			selectors = append(selectors, selector())
+		case css.BeginRulesetGrammar:
+			selectors = append(selectors, selector())
+			if err := skipBlock(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			parent.children = append(parent.children, &cssRule{selectors: selectors, source: b[start:p.Offset()]})
+			selectors = nil
+			start = p.Offset()
+		case css.AtRuleGrammar:
+			parent.children = append(parent.children, &cssRule{source: b[start:p.Offset()]})
+			start = p.Offset()
+		case css.BeginAtRuleGrammar:
+			if cssGroupingAtRules[strings.ToLower(string(data))] {
+				group := &cssRule{header: b[start:p.Offset()]}
+				parent.children = append(parent.children, group)
+				stack = append(stack, group)
+				start = p.Offset()
+				continue
+			}
+			if err := skipBlock(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			parent.children = append(parent.children, &cssRule{source: b[start:p.Offset()]})
+			start = p.Offset()
+		case css.EndAtRuleGrammar:
+			if len(stack) > 1 {
+				parent.footer = b[start:p.Offset()]
+				stack = stack[:len(stack)-1]
+			}
+			start = p.Offset()
+		}
+	}
+}
+
+// normalizeCSSSelector returns s as formatted by the CSS parser, e.g.
+// "nav  >  a" becomes "nav>a".
+func normalizeCSSSelector(s string) string {
+	rules, err := parseCSSRules([]byte(s + "{}"))
+	if err != nil || len(rules) != 1 || len(rules[0].selectors) != 1 {
+		return strings.TrimSpace(s)
+	}
+	return rules[0].selectors[0]
+}
+
+// stripCSSPseudo removes the pseudo-classes and pseudo-elements from the CSS
+// selector s, e.g. "a:hover" becomes "a" and "li:not(.active)::before" becomes "li".
+func stripCSSPseudo(s string) string {
+	var sb strings.Builder
+	var inPseudo bool
+	var parens, brackets int
+	for _, r := range s {
+		switch {
+		case r == '[' && parens == 0:
+			brackets++
+		case r == ']' && parens == 0:
+			brackets--
+		case r == '(':
+			parens++
+			continue
+		case r == ')':
+			parens--
+			continue
+		case r == ':' && brackets == 0:
+			inPseudo = true
+			continue
+		}
+		if parens > 0 {
+			continue
+		}
+		if inPseudo {
+			if r == '-' || r == '_' || isASCIIAlnum(r) {
+				continue
+			}
+			inPseudo = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// cssAlwaysCriticalSelectors are always kept by ExtractCriticalCSS.
+var cssAlwaysCriticalSelectors = map[string]bool{
+	":root": true, "*": true, "html": true,
+}
+
+// ExtractCriticalCSS returns the rules in the stylesheet css that match one
+// of usedSelectors, e.g. the selectors of the above-the-fold elements of a
+// page, for inlining.
+// A rule matches if any of its selectors is in usedSelectors, also when
+// ignoring pseudo-classes and pseudo-elements, so ".btn" keeps ".btn:hover".
+// The rules for :root, * and html are always kept, as are at-rules that are
+// not conditional, e.g. @font-face, @import and @keyframes. Conditional group
+// rules, e.g. @media, are kept with only their matching rules, and dropped if
+// none match.
+// Comments are dropped; the kept rules are left intact.
+func ExtractCriticalCSS(b []byte, usedSelectors []string) ([]byte, error) {
+	rules, err := parseCSSRules(b)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, s := range usedSelectors {
+		used[normalizeCSSSelector(s)] = true
+	}
+
+	isCritical := func(r *cssRule) bool {
+		for _, s := range r.selectors {
+			if used[s] || used[stripCSSPseudo(s)] || cssAlwaysCriticalSelectors[s] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var write func(buf *bytes.Buffer, rules []*cssRule, trim bool) bool
+	write = func(buf *bytes.Buffer, rules []*cssRule, trim bool) bool {
+		var wrote bool
+		for _, r := range rules {
+			var src []byte
+			if r.isGroup() {
+				var inner bytes.Buffer
+				if !write(&inner, r.children, false) {
+					continue
+				}
+				src = append(append(append([]byte(nil), r.header...), inner.Bytes()...), r.footer...)
+			} else if r.selectors == nil || isCritical(r) {
+				src = r.source
+			} else {
+				continue
+			}
+			if trim {
+				src = bytes.TrimSpace(src)
+				if wrote {
+					buf.WriteByte('\n')
+				}
+			}
+			buf.Write(src)
+			wrote = true
+		}
+		return wrote
+	}
+
+	var buf bytes.Buffer
+	if write(&buf, rules, true) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// htmlElement is an element's tag name, id and classes.
+type htmlElement struct {
+	tag     string
+	id      string
+	classes map[string]bool
+}
+
+// UnusedSelectors returns the selectors in the stylesheet css that don't
+// match any element in the HTML document htmlb, in stylesheet order and
+// without duplicates, e.g. to find rules to remove.
+//
+// This is a simple check without a full selector engine: only the rightmost
+// compound selector, e.g. "a.active" in "nav > a.active:hover", is matched
+// against the elements' tag names, ids and classes. Pseudo-classes,
+// pseudo-elements and attribute selectors are ignored, so e.g. "li:nth-child(2)"
+// is considered used if there is any <li> element, and selectors consisting
+// of only those, e.g. "::selection", are always considered used.
+func UnusedSelectors(b []byte, htmlb []byte) ([]string, error) {
+	rules, err := parseCSSRules(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []htmlElement
+	z := html.NewTokenizer(bytes.NewReader(htmlb))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		el := htmlElement{tag: tok.Data, classes: make(map[string]bool)}
+		for _, attr := range tok.Attr {
+			switch attr.Key {
+			case "id":
+				el.id = attr.Val
+			case "class":
+				for _, class := range strings.Fields(attr.Val) {
+					el.classes[class] = true
+				}
+			}
+		}
+		elements = append(elements, el)
+	}
+
+	var unused []string
+	seen := make(map[string]bool)
+	var walk func(rules []*cssRule)
+	walk = func(rules []*cssRule) {
+		for _, r := range rules {
+			walk(r.children)
+			for _, s := range r.selectors {
+				if seen[s] {
+					continue
+				}
+				seen[s] = true
+				if !cssSelectorIsUsed(s, elements) {
+					unused = append(unused, s)
+				}
+			}
+		}
+	}
+	walk(rules)
+
+	return unused, nil
+}
+
+func cssSelectorIsUsed(selector string, elements []htmlElement) bool {
+	compound := stripCSSPseudo(selector)
+	if i := strings.LastIndexAny(compound, " >+~"); i != -1 {
+		compound = compound[i+1:]
+	}
+
+	// Parse e.g. "a.active#main[href]" into its tag name, classes and id.
+	var tag, id string
+	var classes []string
+	for compound != "" {
+		var kind byte
+		switch compound[0] {
+		case '.', '#':
+			kind = compound[0]
+			compound = compound[1:]
+		case '[':
+			end := strings.IndexByte(compound, ']')
+			if end == -1 {
+				end = len(compound) - 1
+			}
+			compound = compound[end+1:]
+			continue
+		}
+		end := strings.IndexAny(compound, ".#[")
+		if end == -1 {
+			end = len(compound)
+		}
+		name := compound[:end]
+		compound = compound[end:]
+		switch kind {
+		case '.':
+			classes = append(classes, name)
+		case '#':
+			id = name
+		default:
+			tag = strings.ToLower(name)
+		}
+	}
+
+	if tag == "*" {
+		tag = ""
+	}
+	if tag == "" && id == "" && len(classes) == 0 {
+		return true
+	}
+
+	for _, el := range elements {
+		if tag != "" && el.tag != tag {
+			continue
+		}
+		if id != "" && el.id != id {
+			continue
+		}
+		matches := true
+		for _, class := range classes {
+			if !el.classes[class] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+var cssURLRe = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^'"\s)]*))\s*\)`)
+
+// RewriteCSSURLs replaces the values of the url() references in css with
+// their value in mapping, e.g. to point to fingerprinted images and fonts.
+// The quoting and whitespace of the url() is preserved.
+// Data URIs, absolute URLs (with a scheme or protocol-relative) and values
+// not in mapping are left untouched.
+func RewriteCSSURLs(css []byte, mapping map[string]string) []byte {
+	return cssURLRe.ReplaceAllFunc(css, func(m []byte) []byte {
+		sm := cssURLRe.FindSubmatchIndex(m)
+		for i := 2; i < len(sm); i += 2 {
+			if sm[i] == -1 {
+				continue
+			}
+			value := string(m[sm[i]:sm[i+1]])
+			if isAbsoluteOrDataURL(value) {
+				return m
+			}
+			replacement, found := mapping[value]
+			if !found {
+				return m
+			}
+			var b []byte
+			b = append(b, m[:sm[i]]...)
+			b = append(b, replacement...)
+			return append(b, m[sm[i+1]:]...)
+		}
+		return m
+	})
+}
+
+func isAbsoluteOrDataURL(s string) bool {
+	if strings.HasPrefix(s, "//") {
+		return true
+	}
+	// A scheme, e.g. "https:" or "data:", see RFC 3986 section 3.1.
+	i := strings.IndexByte(s, ':')
+	if i <= 0 {
+		return false
+	}
+	for j, r := range s[:i] {
+		isLetter := 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+		if !isLetter && (j == 0 || !isASCIIAlnum(r) && r != '+' && r != '-' && r != '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+const testCriticalCSS = `@charset "utf-8";
+/* Variables */
+:root {
+  --accent: #f00;
+}
+
+@font-face {
+  font-family: "Inter";
+  src: url("/fonts/inter.woff2") format("woff2");
+}
+
+h1, .title {
+  font-size: 2rem;
+}
+
+.hero { background: var(--accent); }
+
+.btn:hover { color: red }
+
+nav > a { color: blue; }
+
+.footer {
+  margin-top: 4rem;
+}
+
+@media (min-width: 768px) {
+  .hero { padding: 2rem; }
+  .sidebar { display: block; }
+}
+
+@media print {
+  .footer { display: none; }
+}
+`
+
+func TestExtractCriticalCSS(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := helpers.ExtractCriticalCSS([]byte(testCriticalCSS), []string{".title", ".hero", ".btn", "nav  >  a"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `@charset "utf-8";
+:root {
+  --accent: #f00;
+}
+@font-face {
+  font-family: "Inter";
+  src: url("/fonts/inter.woff2") format("woff2");
+}
+h1, .title {
+  font-size: 2rem;
+}
+.hero { background: var(--accent); }
+.btn:hover { color: red }
+nav > a { color: blue; }
+@media (min-width: 768px) {
+  .hero { padding: 2rem; }
+}
+`)
+
+	b, err = helpers.ExtractCriticalCSS([]byte(".a { color: red }"), []string{".b"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "")
+}
+
+func TestUnusedSelectors(t *testing.T) {
+	c := qt.New(t)
+
+	css := `
+.used { color: red; }
+.unused { color: blue; }
+h1, h2 { margin: 0; }
+#main > p.lead:first-child { font-weight: bold; }
+#sidebar { display: none; }
+nav a.active:hover { color: green; }
+nav a.current { color: green; }
+input[type="text"] { border: 0; }
+::selection { color: white; }
+@media (min-width: 768px) {
+  .used { padding: 1rem; }
+  .wide { width: 100%; }
+}
+`
+	page := `<html><body>
+<nav><a class="active button" href="/">Home</a></nav>
+<main id="main"><h1 class="used">Title</h1><p class="lead">Lead</p><input type="text"></main>
+</body></html>`
+
+	unused, err := helpers.UnusedSelectors([]byte(css), []byte(page))
+	c.Assert(err, qt.IsNil)
+	c.Assert(unused, qt.DeepEquals, []string{".unused", "h2", "#sidebar", "nav a.current", ".wide"})
+
+	unused, err = helpers.UnusedSelectors([]byte(css), nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(unused, qt.Not(qt.Contains), "::selection")
+	c.Assert(unused, qt.Contains, ".used")
+}
+
+func TestRewriteCSSURLs(t *testing.T) {
+	c := qt.New(t)
+
+	mapping := map[string]string{
+		"/images/bg.png":            "/images/bg.abc123.png",
+		"../fonts/inter.woff2":      "../fonts/inter.def456.woff2",
+		"logo.svg":                  "logo.789.svg",
+		"https://example.org/a.png": "/should-not-be-used.png",
+	}
+
+	css := `body { background: url(/images/bg.png) no-repeat; }
+@font-face { src: url("../fonts/inter.woff2") format("woff2"); }
+.logo { background-image: URL( 'logo.svg' ); }
+.icon { background: url(data:image/png;base64,iVBORw0KGgo=); }
+.remote { background: url("https://example.org/a.png"); }
+.other { background: url(/images/other.png); }
+`
+	c.Assert(string(helpers.RewriteCSSURLs([]byte(css), mapping)), qt.Equals, `body { background: url(/images/bg.abc123.png) no-repeat; }
+@font-face { src: url("../fonts/inter.def456.woff2") format("woff2"); }
+.logo { background-image: URL( 'logo.789.svg' ); }
+.icon { background: url(data:image/png;base64,iVBORw0KGgo=); }
+.remote { background: url("https://example.org/a.png"); }
+.other { background: url(/images/other.png); }
+`)
+
+	c.Assert(string(helpers.RewriteCSSURLs([]byte(".a { color: red }"), mapping)), qt.Equals, ".a { color: red }")
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBudgetExceeded is returned (wrapped) by WithDeadline when the time
+// budget is exceeded.
+var ErrBudgetExceeded = errors.New("build exceeded budget")
+
+// WithDeadline runs fn with a context derived from ctx that expires after
+// budget. If the budget is exceeded, an error wrapping ErrBudgetExceeded is
+// returned without waiting for fn to return, so fn should watch ctx.Done to
+// stop its work. A budget <= 0 means no budget.
+func WithDeadline(ctx context.Context, budget time.Duration, fn func(context.Context) error) error {
+	if budget <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w of %s: %s", ErrBudgetExceeded, budget, err)
+		}
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w of %s", ErrBudgetExceeded, budget)
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestWithDeadline(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+
+	c.Run("Fast", func(c *qt.C) {
+		err := helpers.WithDeadline(ctx, time.Minute, func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			c.Assert(hasDeadline, qt.IsTrue)
+			return nil
+		})
+		c.Assert(err, qt.IsNil)
+	})
+
+	c.Run("Error", func(c *qt.C) {
+		myErr := errors.New("failed")
+		err := helpers.WithDeadline(ctx, time.Minute, func(ctx context.Context) error {
+			return myErr
+		})
+		c.Assert(err, qt.Equals, myErr)
+	})
+
+	c.Run("Slow", func(c *qt.C) {
+		err := helpers.WithDeadline(ctx, 20*time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		c.Assert(errors.Is(err, helpers.ErrBudgetExceeded), qt.IsTrue)
+		c.Assert(err, qt.ErrorMatches, "build exceeded budget of 20ms.*")
+	})
+
+	c.Run("Slow, ignores context", func(c *qt.C) {
+		release := make(chan struct{})
+		defer close(release)
+		start := time.Now()
+		err := helpers.WithDeadline(ctx, 20*time.Millisecond, func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		c.Assert(errors.Is(err, helpers.ErrBudgetExceeded), qt.IsTrue)
+		c.Assert(time.Since(start) < 10*time.Second, qt.IsTrue)
+	})
+
+	c.Run("Parent canceled", func(c *qt.C) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := helpers.WithDeadline(ctx, time.Minute, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		c.Assert(err, qt.Equals, context.Canceled)
+	})
+
+	c.Run("No budget", func(c *qt.C) {
+		err := helpers.WithDeadline(ctx, 0, func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			c.Assert(hasDeadline, qt.IsFalse)
+			return nil
+		})
+		c.Assert(err, qt.IsNil)
+	})
+}
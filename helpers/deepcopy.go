@@ -0,0 +1,139 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DeepCopy returns a copy of v that shares no maps, slices or pointers with v,
+// so either can be modified without affecting the other.
+// Cyclic and shared pointers are preserved in the copy.
+// Struct fields that aren't exported are copied as is, i.e. shallowly.
+// Functions, channels and unsafe pointers can't be copied; an error is
+// returned if v contains a non-nil one.
+func DeepCopy[T any](v T) (T, error) {
+	var cp T
+	c := deepCopier{pointers: make(map[deepCopyPointer]reflect.Value)}
+	v2, err := c.copy(reflect.ValueOf(&v).Elem())
+	if err != nil {
+		return cp, err
+	}
+	reflect.ValueOf(&cp).Elem().Set(v2)
+	return cp, nil
+}
+
+type deepCopier struct {
+	// Maps the pointers seen to their copies.
+	pointers map[deepCopyPointer]reflect.Value
+}
+
+// A pointer to a struct and to its first field have the same address,
+// so the type is needed to tell them apart.
+type deepCopyPointer struct {
+	t reflect.Type
+	p uintptr
+}
+
+func (c deepCopier) copy(v reflect.Value) (reflect.Value, error) {
+	cp := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if !v.IsNil() {
+			return cp, fmt.Errorf("deep copy: unsupported type %s", v.Type())
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return cp, nil
+		}
+		key := deepCopyPointer{t: v.Type(), p: v.Pointer()}
+		if p, found := c.pointers[key]; found {
+			return p, nil
+		}
+		cp = reflect.New(v.Type().Elem())
+		c.pointers[key] = cp
+		elem, err := c.copy(v.Elem())
+		if err != nil {
+			return cp, err
+		}
+		cp.Elem().Set(elem)
+	case reflect.Interface:
+		if v.IsNil() {
+			return cp, nil
+		}
+		elem, err := c.copy(v.Elem())
+		if err != nil {
+			return cp, err
+		}
+		cp.Set(elem)
+	case reflect.Map:
+		if v.IsNil() {
+			return cp, nil
+		}
+		cp = reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := c.copy(iter.Key())
+			if err != nil {
+				return cp, err
+			}
+			elem, err := c.copy(iter.Value())
+			if err != nil {
+				return cp, err
+			}
+			cp.SetMapIndex(key, elem)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return cp, nil
+		}
+		cp = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		if err := c.copyElements(cp, v); err != nil {
+			return cp, err
+		}
+	case reflect.Array:
+		if err := c.copyElements(cp, v); err != nil {
+			return cp, err
+		}
+	case reflect.Struct:
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !cp.Field(i).CanSet() {
+				continue
+			}
+			field, err := c.copy(v.Field(i))
+			if err != nil {
+				return cp, fmt.Errorf("%w (field %s.%s)", err, v.Type(), v.Type().Field(i).Name)
+			}
+			cp.Field(i).Set(field)
+		}
+	default:
+		cp.Set(v)
+	}
+
+	return cp, nil
+}
+
+func (c deepCopier) copyElements(dst, src reflect.Value) error {
+	for i := 0; i < src.Len(); i++ {
+		elem, err := c.copy(src.Index(i))
+		if err != nil {
+			return err
+		}
+		dst.Index(i).Set(elem)
+	}
+	return nil
+}
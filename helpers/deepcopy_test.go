@@ -0,0 +1,107 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestDeepCopy(t *testing.T) {
+	c := qt.New(t)
+
+	original := map[string]any{
+		"title": "Hugo",
+		"tags":  []any{"a", "b"},
+		"author": map[string]any{
+			"name":  "Bep",
+			"sites": []string{"gohugo.io"},
+		},
+		"weights": [2]int{1, 2},
+	}
+
+	cp, err := helpers.DeepCopy(original)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cp, qt.DeepEquals, original)
+
+	cp["title"] = "Hugo!"
+	cp["tags"].([]any)[0] = "c"
+	author := cp["author"].(map[string]any)
+	author["name"] = "Someone"
+	author["sites"].([]string)[0] = "example.org"
+
+	c.Assert(original["title"], qt.Equals, "Hugo")
+	c.Assert(original["tags"], qt.DeepEquals, []any{"a", "b"})
+	c.Assert(original["author"], qt.DeepEquals, map[string]any{
+		"name":  "Bep",
+		"sites": []string{"gohugo.io"},
+	})
+
+	// Scalars and nil.
+	n, err := helpers.DeepCopy(42)
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 42)
+	var nilMap map[string]any
+	m, err := helpers.DeepCopy(nilMap)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.IsNil)
+	var nilAny any
+	a, err := helpers.DeepCopy(nilAny)
+	c.Assert(err, qt.IsNil)
+	c.Assert(a, qt.IsNil)
+
+	// Unsupported types.
+	_, err = helpers.DeepCopy(map[string]any{"f": func() {}})
+	c.Assert(err, qt.ErrorMatches, `deep copy: unsupported type func\(\)`)
+	_, err = helpers.DeepCopy([]any{make(chan int)})
+	c.Assert(err, qt.ErrorMatches, "deep copy: unsupported type chan int")
+	_, err = helpers.DeepCopy(map[string]any{"f": (func())(nil)})
+	c.Assert(err, qt.IsNil)
+}
+
+type deepCopyNode struct {
+	Name     string
+	Children []*deepCopyNode
+	Parent   *deepCopyNode
+	Handler  func()
+	private  []int
+}
+
+func TestDeepCopyStruct(t *testing.T) {
+	c := qt.New(t)
+
+	root := &deepCopyNode{Name: "root", private: []int{1}}
+	child := &deepCopyNode{Name: "child", Parent: root}
+	root.Children = []*deepCopyNode{child, child}
+
+	cp, err := helpers.DeepCopy(root)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cp, qt.Not(qt.Equals), root)
+	c.Assert(cp.Children[0], qt.Not(qt.Equals), child)
+	c.Assert(cp.Children[0].Parent, qt.Equals, cp)
+	c.Assert(cp.Children[0], qt.Equals, cp.Children[1])
+
+	cp.Children[0].Name = "changed"
+	c.Assert(child.Name, qt.Equals, "child")
+
+	// Unexported fields are copied shallowly.
+	cp.private[0] = 2
+	c.Assert(root.private[0], qt.Equals, 2)
+
+	root.Handler = func() {}
+	_, err = helpers.DeepCopy(root)
+	c.Assert(err, qt.ErrorMatches, `deep copy: unsupported type func\(\) \(field helpers_test.deepCopyNode.Handler\)`)
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"sort"
+	"sync"
+)
+
+// AffectedPages returns the sorted pages that need to be rebuilt when the
+// changed files have changed, given the dependencies of each page in deps.
+// A page is affected if it has changed itself or if any of its dependencies
+// are affected, where a dependency may itself be a page in deps, so the
+// changes propagate transitively. Cyclic dependencies are allowed.
+func AffectedPages(changed []string, deps map[string][]string) []string {
+	dependents := make(map[string][]string)
+	for page, pageDeps := range deps {
+		for _, dep := range pageDeps {
+			dependents[dep] = append(dependents[dep], page)
+		}
+	}
+
+	seen := make(map[string]bool)
+	queue := append([]string(nil), changed...)
+	for _, c := range changed {
+		seen[c] = true
+	}
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		for _, page := range dependents[item] {
+			if !seen[page] {
+				seen[page] = true
+				queue = append(queue, page)
+			}
+		}
+	}
+
+	var pages []string
+	for item := range seen {
+		if _, isPage := deps[item]; isPage {
+			pages = append(pages, item)
+		}
+	}
+	sort.Strings(pages)
+	return pages
+}
+
+// DependencyTracker records which pages depend on which dependencies, e.g.
+// templates and data files, during rendering.
+// It's safe for concurrent use, and the zero value is ready to use.
+type DependencyTracker struct {
+	mu         sync.RWMutex
+	dependents map[string]map[string]bool // dependency => pages
+	deps       map[string]map[string]bool // page => dependencies
+}
+
+// Record records that page depends on dependency.
+func (t *DependencyTracker) Record(page, dependency string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dependents == nil {
+		t.dependents = make(map[string]map[string]bool)
+		t.deps = make(map[string]map[string]bool)
+	}
+	if t.dependents[dependency] == nil {
+		t.dependents[dependency] = make(map[string]bool)
+	}
+	t.dependents[dependency][page] = true
+	if t.deps[page] == nil {
+		t.deps[page] = make(map[string]bool)
+	}
+	t.deps[page][dependency] = true
+}
+
+// Dependents returns the sorted pages recorded to depend directly on
+// dependency.
+func (t *DependencyTracker) Dependents(dependency string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return sortedKeys(t.dependents[dependency])
+}
+
+// Graph returns the sorted dependencies of each page, as expected by
+// AffectedPages.
+func (t *DependencyTracker) Graph() map[string][]string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	graph := make(map[string][]string, len(t.deps))
+	for page, deps := range t.deps {
+		graph[page] = sortedKeys(deps)
+	}
+	return graph
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
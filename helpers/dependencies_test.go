@@ -0,0 +1,108 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestAffectedPages(t *testing.T) {
+	c := qt.New(t)
+
+	deps := map[string][]string{
+		"content/about.md":     {"layouts/_default/single.html", "data/team.yaml"},
+		"content/blog/a.md":    {"layouts/_default/single.html", "content/blog/b.md"},
+		"content/blog/b.md":    {"layouts/partials/related.html"},
+		"content/blog/c.md":    {"content/blog/b.md"},
+		"content/_index.md":    {"layouts/index.html", "content/blog/a.md"},
+		"content/cycle/x.md":   {"content/cycle/y.md", "assets/x.scss"},
+		"content/cycle/y.md":   {"content/cycle/x.md"},
+		"content/no-deps.md":   nil,
+		"content/unrelated.md": {"layouts/_default/list.html"},
+	}
+
+	// Direct.
+	c.Assert(helpers.AffectedPages([]string{"data/team.yaml"}, deps), qt.DeepEquals, []string{"content/about.md"})
+
+	// Transitive chain.
+	c.Assert(helpers.AffectedPages([]string{"layouts/partials/related.html"}, deps), qt.DeepEquals, []string{
+		"content/_index.md",
+		"content/blog/a.md",
+		"content/blog/b.md",
+		"content/blog/c.md",
+	})
+
+	// Shared dependency.
+	c.Assert(helpers.AffectedPages([]string{"layouts/_default/single.html"}, deps), qt.DeepEquals, []string{
+		"content/_index.md",
+		"content/about.md",
+		"content/blog/a.md",
+	})
+
+	// Cycle.
+	c.Assert(helpers.AffectedPages([]string{"assets/x.scss"}, deps), qt.DeepEquals, []string{
+		"content/cycle/x.md",
+		"content/cycle/y.md",
+	})
+
+	// A changed page.
+	c.Assert(helpers.AffectedPages([]string{"content/no-deps.md", "content/blog/c.md"}, deps), qt.DeepEquals, []string{
+		"content/blog/c.md",
+		"content/no-deps.md",
+	})
+
+	c.Assert(helpers.AffectedPages([]string{"static/logo.png"}, deps), qt.HasLen, 0)
+	c.Assert(helpers.AffectedPages(nil, deps), qt.HasLen, 0)
+}
+
+func TestDependencyTracker(t *testing.T) {
+	c := qt.New(t)
+
+	var tracker helpers.DependencyTracker
+	c.Assert(tracker.Dependents("layouts/index.html"), qt.IsNil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			page := fmt.Sprintf("content/p%02d.md", i)
+			tracker.Record(page, "layouts/_default/single.html")
+			tracker.Record(page, "layouts/_default/single.html")
+			if i%2 == 0 {
+				tracker.Record(page, "data/even.yaml")
+			}
+			tracker.Dependents("data/even.yaml")
+		}(i)
+	}
+	wg.Wait()
+
+	c.Assert(tracker.Dependents("layouts/_default/single.html"), qt.HasLen, 20)
+	c.Assert(tracker.Dependents("data/even.yaml"), qt.DeepEquals, []string{
+		"content/p00.md", "content/p02.md", "content/p04.md", "content/p06.md", "content/p08.md",
+		"content/p10.md", "content/p12.md", "content/p14.md", "content/p16.md", "content/p18.md",
+	})
+	c.Assert(tracker.Dependents("data/missing.yaml"), qt.IsNil)
+
+	graph := tracker.Graph()
+	c.Assert(graph, qt.HasLen, 20)
+	c.Assert(graph["content/p00.md"], qt.DeepEquals, []string{"data/even.yaml", "layouts/_default/single.html"})
+	c.Assert(graph["content/p01.md"], qt.DeepEquals, []string{"layouts/_default/single.html"})
+	c.Assert(helpers.AffectedPages([]string{"data/even.yaml"}, graph), qt.HasLen, 10)
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NormalizeEmail validates the basic structure of the email address s
+// (local-part@domain, see RFC 5322) and returns it with surrounding whitespace
+// trimmed and the domain lower cased. The local part is case sensitive and kept as is.
+// Quoted local parts and IP address literals are not supported.
+func NormalizeEmail(s string) (string, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.LastIndex(s, "@")
+	if i == -1 {
+		return "", fmt.Errorf("invalid email address %q: missing @", s)
+	}
+	local, domain := s[:i], strings.ToLower(s[i+1:])
+
+	if err := validateEmailLocalPart(local); err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", s, err)
+	}
+	if err := validateEmailDomain(domain); err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", s, err)
+	}
+
+	return local + "@" + domain, nil
+}
+
+func validateEmailLocalPart(local string) error {
+	if local == "" {
+		return errors.New("empty local part")
+	}
+	if len(local) > 64 {
+		return errors.New("local part too long")
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return errors.New("misplaced dot in local part")
+	}
+	for _, r := range local {
+		if r == '.' || r > unicode.MaxASCII && unicode.IsPrint(r) && !unicode.IsSpace(r) ||
+			r < unicode.MaxASCII && (isASCIIAlnum(r) || strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r)) {
+			continue
+		}
+		return fmt.Errorf("invalid character %q in local part", r)
+	}
+	return nil
+}
+
+func validateEmailDomain(domain string) error {
+	if len(domain) > 253 {
+		return errors.New("domain too long")
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return errors.New("domain must have at least two labels")
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("invalid domain label %q", label)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("domain label %q must not start or end with a hyphen", label)
+		}
+		for _, r := range label {
+			if r == '-' || isASCIIAlnum(r) || r > unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)) {
+				continue
+			}
+			return fmt.Errorf("invalid character %q in domain", r)
+		}
+	}
+	return nil
+}
+
+func isASCIIAlnum(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
+
+// ObfuscateEmail encodes email to hide it from naive scrapers. The supported
+// methods are:
+//
+// - "hex": every character is written as a hexadecimal HTML character reference,
+// e.g. "&#x6a;", which the browser renders as the original text.
+// - "js": every character is written as a JavaScript hexadecimal escape sequence,
+// e.g. "\x6a", suitable for a string literal passed to document.write or similar.
+func ObfuscateEmail(email string, method string) (string, error) {
+	var b strings.Builder
+	switch strings.ToLower(method) {
+	case "hex":
+		for _, r := range email {
+			fmt.Fprintf(&b, "&#x%x;", r)
+		}
+	case "js":
+		for _, r := range email {
+			if r > 0xff {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				fmt.Fprintf(&b, `\x%02x`, r)
+			}
+		}
+	default:
+		return "", fmt.Errorf("unsupported email obfuscation method %q, use either hex or js", method)
+	}
+	return b.String(), nil
+}
+
+const (
+	gravatarMinSize = 1
+	gravatarMaxSize = 2048
+)
+
+// GravatarURL returns the Gravatar image URL for email, see
+// https://docs.gravatar.com/general/images/
+// The size is clamped to the range supported by Gravatar (1-2048); a size
+// of 0 leaves the choice to Gravatar (80px).
+// defaultImage is either one of the Gravatar keywords, e.g. "identicon" or "mp",
+// or the URL to a fallback image, and is omitted if empty.
+func GravatarURL(email string, size int, defaultImage string) string {
+	hash := MD5String(strings.ToLower(strings.TrimSpace(email)))
+
+	params := url.Values{}
+	if size != 0 {
+		if size < gravatarMinSize {
+			size = gravatarMinSize
+		} else if size > gravatarMaxSize {
+			size = gravatarMaxSize
+		}
+		params.Set("s", strconv.Itoa(size))
+	}
+	if defaultImage != "" {
+		params.Set("d", defaultImage)
+	}
+
+	u := "https://www.gravatar.com/avatar/" + hash
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return u
+}
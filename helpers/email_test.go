@@ -0,0 +1,94 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"html"
+	"strconv"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"jane@example.org", "jane@example.org"},
+		{"  Jane.Doe+hugo@Example.ORG ", "Jane.Doe+hugo@example.org"},
+		{"o'neil@mail.example.co.uk", "o'neil@mail.example.co.uk"},
+		{"jürgen@bücher.de", "jürgen@bücher.de"},
+	} {
+		got, err := helpers.NormalizeEmail(test.in)
+		c.Assert(err, qt.IsNil, qt.Commentf(test.in))
+		c.Assert(got, qt.Equals, test.expect)
+	}
+
+	for _, in := range []string{
+		"",
+		"jane",
+		"@example.org",
+		"jane@",
+		"jane@localhost",
+		"jane doe@example.org",
+		"jane..doe@example.org",
+		".jane@example.org",
+		"jane@-example.org",
+		"jane@example..org",
+		"jane@exa_mple.org",
+		"Jane <jane@example.org>",
+	} {
+		_, err := helpers.NormalizeEmail(in)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(in))
+	}
+}
+
+func TestObfuscateEmail(t *testing.T) {
+	c := qt.New(t)
+	email := "jane@example.org"
+
+	hex, err := helpers.ObfuscateEmail(email, "hex")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hex, qt.Not(qt.Contains), "@")
+	c.Assert(hex[:12], qt.Equals, "&#x6a;&#x61;")
+	c.Assert(html.UnescapeString(hex), qt.Equals, email)
+
+	js, err := helpers.ObfuscateEmail(email, "js")
+	c.Assert(err, qt.IsNil)
+	c.Assert(js[:8], qt.Equals, `\x6a\x61`)
+	unquoted, err := strconv.Unquote(`"` + js + `"`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(unquoted, qt.Equals, email)
+
+	js, err = helpers.ObfuscateEmail("ø€", "js")
+	c.Assert(err, qt.IsNil)
+	c.Assert(js, qt.Equals, `\xf8\u20ac`)
+
+	_, err = helpers.ObfuscateEmail(email, "rot13")
+	c.Assert(err, qt.ErrorMatches, `unsupported email obfuscation method "rot13".*`)
+}
+
+func TestGravatarURL(t *testing.T) {
+	c := qt.New(t)
+
+	// Example from https://docs.gravatar.com/general/hash/
+	c.Assert(helpers.GravatarURL(" MyEmailAddress@example.com ", 0, ""), qt.Equals, "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346")
+	c.Assert(helpers.GravatarURL("myemailaddress@example.com", 200, "identicon"), qt.Equals, "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=identicon&s=200")
+	c.Assert(helpers.GravatarURL("myemailaddress@example.com", 5000, "https://example.org/avatar.png"), qt.Equals, "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=https%3A%2F%2Fexample.org%2Favatar.png&s=2048")
+	c.Assert(helpers.GravatarURL("myemailaddress@example.com", -1, ""), qt.Equals, "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?s=1")
+}
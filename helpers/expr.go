@@ -0,0 +1,222 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// EvalExpr evaluates a simple arithmetic expression, e.g. "(width - 2 * gutter) / 3",
+// with the given variables.
+// The supported operators are + - * / % (with the usual precedence), unary
+// minus and plus, and parentheses. Numbers are floats, e.g. "3", "0.5" or "1e3".
+// Variable names start with a letter or an underscore followed by letters, digits or underscores.
+// The % operator is the floating-point remainder (math.Mod).
+func EvalExpr(expr string, vars map[string]float64) (float64, error) {
+	p := &exprParser{input: expr, vars: vars}
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate %q: %w", expr, err)
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("failed to evaluate %q: unexpected %q at position %d", expr, p.input[p.pos], p.pos)
+	}
+	return v, nil
+}
+
+// exprParser is a recursive descent parser for the grammar:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = unary { ("*" | "/" | "%") unary }
+//	unary  = ("+" | "-") unary | factor
+//	factor = number | identifier | "(" expr ")"
+type exprParser struct {
+	input string
+	pos   int
+	depth int
+	vars  map[string]float64
+}
+
+// exprMaxDepth limits the nesting of parentheses and unary operators.
+const exprMaxDepth = 100
+
+func (p *exprParser) parseExpr() (float64, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *exprParser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			left *= right
+		case '/':
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			left /= right
+		case '%':
+			if right == 0 {
+				return 0, errors.New("modulo by zero")
+			}
+			left = math.Mod(left, right)
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	p.skipSpace()
+	if p.pos < len(p.input) && (p.input[p.pos] == '-' || p.input[p.pos] == '+') {
+		op := p.input[p.pos]
+		p.pos++
+		if err := p.enter(); err != nil {
+			return 0, err
+		}
+		v, err := p.parseUnary()
+		p.depth--
+		if err != nil {
+			return 0, err
+		}
+		if op == '-' {
+			v = -v
+		}
+		return v, nil
+	}
+	return p.parseFactor()
+}
+
+func (p *exprParser) parseFactor() (float64, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0, errors.New("unexpected end of expression")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		if err := p.enter(); err != nil {
+			return 0, err
+		}
+		v, err := p.parseExpr()
+		p.depth--
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return 0, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return v, nil
+	case isExprDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (isExprDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		// Exponent, e.g. 1e3 or 2.5E-2.
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.input) && isExprDigit(p.input[p.pos]) {
+				p.pos++
+			}
+		}
+		v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q at position %d", p.input[start:p.pos], start)
+		}
+		return v, nil
+	case isExprIdentStart(c):
+		start := p.pos
+		for p.pos < len(p.input) && (isExprIdentStart(p.input[p.pos]) || isExprDigit(p.input[p.pos])) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		v, found := p.vars[name]
+		if !found {
+			return 0, fmt.Errorf("unknown variable %q", name)
+		}
+		return v, nil
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+func (p *exprParser) enter() error {
+	p.depth++
+	if p.depth > exprMaxDepth {
+		return errors.New("expression is nested too deeply")
+	}
+	return nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && IsWhitespace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func isExprDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isExprIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
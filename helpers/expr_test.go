@@ -0,0 +1,70 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestEvalExpr(t *testing.T) {
+	c := qt.New(t)
+	vars := map[string]float64{"width": 960, "gutter": 30, "n": 3, "_x1": 2}
+
+	for _, test := range []struct {
+		expr   string
+		expect float64
+	}{
+		{"2+3*4", 14},
+		{"(2+3)*4", 20},
+		{"2*3+4", 10},
+		{"10-4-3", 3},
+		{"48/4/2", 6},
+		{"7 % 3", 1},
+		{"-2*3", -6},
+		{"-(2+3)", -5},
+		{"--2", 2},
+		{"+2", 2},
+		{"1.5e2 + .5", 150.5},
+		{" ( width - 2 * gutter ) / n ", 300},
+		{"_x1 * _x1", 4},
+	} {
+		v, err := helpers.EvalExpr(test.expr, vars)
+		c.Assert(err, qt.IsNil, qt.Commentf(test.expr))
+		c.Assert(v, qt.Equals, test.expect, qt.Commentf(test.expr))
+	}
+
+	for _, test := range []struct {
+		expr   string
+		errMsg string
+	}{
+		{"1/0", ".*division by zero"},
+		{"1/(n-3)", ".*division by zero"},
+		{"1%0", ".*modulo by zero"},
+		{"foo + 1", `.*unknown variable "foo"`},
+		{"(1+2", ".*missing closing parenthesis.*"},
+		{"1+2)", `.*unexpected '\)' at position 3`},
+		{"1+", ".*unexpected end of expression"},
+		{"", ".*unexpected end of expression"},
+		{"2 $ 3", `.*unexpected '\$' at position 2`},
+		{"1..2", `.*invalid number "1..2".*`},
+		{strings.Repeat("(", 200) + "1" + strings.Repeat(")", 200), ".*nested too deeply"},
+	} {
+		_, err := helpers.EvalExpr(test.expr, vars)
+		c.Assert(err, qt.ErrorMatches, test.errMsg, qt.Commentf(test.expr))
+	}
+}
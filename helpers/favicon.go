@@ -0,0 +1,101 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+
+	"github.com/disintegration/gift"
+	"golang.org/x/exp/slices"
+)
+
+// maxICOSize is the largest image size that can be stored in an ICO file.
+const maxICOSize = 256
+
+// GenerateFavicons resizes src to each of the given square sizes in pixels,
+// cropping it to a square around its center if needed, and returns the
+// PNG encoded favicons keyed by filename, e.g. "favicon-32x32.png", along with
+// a "favicon.ico" holding all of them. The sizes must be between 1 and 256.
+func GenerateFavicons(src image.Image, sizes []int) (map[string][]byte, error) {
+	if len(sizes) == 0 {
+		return nil, errors.New("no favicon sizes given")
+	}
+	for _, size := range sizes {
+		if size <= 0 || size > maxICOSize {
+			return nil, fmt.Errorf("favicon size must be between 1 and %d, got %d", maxICOSize, size)
+		}
+	}
+	sizes = UniqueSorted(slices.Clone(sizes))
+
+	favicons := make(map[string][]byte, len(sizes)+1)
+	var icoImages [][]byte
+	for _, size := range sizes {
+		g := gift.New(gift.ResizeToFill(size, size, gift.LanczosResampling, gift.CenterAnchor))
+		dst := image.NewNRGBA(g.Bounds(src.Bounds()))
+		g.Draw(dst, src)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, err
+		}
+		favicons[fmt.Sprintf("favicon-%dx%d.png", size, size)] = buf.Bytes()
+		icoImages = append(icoImages, buf.Bytes())
+	}
+
+	favicons["favicon.ico"] = encodeICO(sizes, icoImages)
+
+	return favicons, nil
+}
+
+// encodeICO returns an ICO file holding the given PNG encoded square images
+// with the given sizes, which must be at most 256.
+func encodeICO(sizes []int, pngs [][]byte) []byte {
+	const headerLen, entryLen = 6, 16
+
+	var buf bytes.Buffer
+	w := func(v any) {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+
+	// ICONDIR
+	w(uint16(0)) // Reserved
+	w(uint16(1)) // Type: icon
+	w(uint16(len(pngs)))
+
+	// ICONDIRENTRY for each image; a width and height of 0 means 256.
+	offset := headerLen + entryLen*len(pngs)
+	for i, b := range pngs {
+		dim := uint8(sizes[i] % maxICOSize)
+		w(dim)        // Width
+		w(dim)        // Height
+		w(uint8(0))   // Number of palette colors
+		w(uint8(0))   // Reserved
+		w(uint16(1))  // Color planes
+		w(uint16(32)) // Bits per pixel
+		w(uint32(len(b)))
+		w(uint32(offset))
+		offset += len(b)
+	}
+
+	for _, b := range pngs {
+		buf.Write(b)
+	}
+
+	return buf.Bytes()
+}
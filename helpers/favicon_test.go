@@ -0,0 +1,68 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestGenerateFavicons(t *testing.T) {
+	c := qt.New(t)
+
+	sizes := []int{32, 16, 256, 32}
+	favicons, err := helpers.GenerateFavicons(newTestImage(600, 400), sizes)
+	c.Assert(err, qt.IsNil)
+	c.Assert(sizes, qt.DeepEquals, []int{32, 16, 256, 32})
+	c.Assert(favicons, qt.HasLen, 4)
+
+	for _, size := range []int{16, 32, 256} {
+		b, found := favicons[fmt.Sprintf("favicon-%dx%d.png", size, size)]
+		c.Assert(found, qt.IsTrue, qt.Commentf("%d", size))
+		img, err := png.Decode(bytes.NewReader(b))
+		c.Assert(err, qt.IsNil)
+		c.Assert(img.Bounds(), qt.Equals, image.Rect(0, 0, size, size))
+	}
+
+	ico := favicons["favicon.ico"]
+	c.Assert(binary.LittleEndian.Uint16(ico[2:]), qt.Equals, uint16(1))
+	count := int(binary.LittleEndian.Uint16(ico[4:]))
+	c.Assert(count, qt.Equals, 3)
+	for i, size := range []int{16, 32, 256} {
+		entry := ico[6+16*i:]
+		c.Assert(int(entry[0]), qt.Equals, size%256)
+		c.Assert(int(entry[1]), qt.Equals, size%256)
+		length := binary.LittleEndian.Uint32(entry[8:])
+		offset := binary.LittleEndian.Uint32(entry[12:])
+		img, err := png.Decode(bytes.NewReader(ico[offset : offset+length]))
+		c.Assert(err, qt.IsNil)
+		c.Assert(img.Bounds(), qt.Equals, image.Rect(0, 0, size, size))
+	}
+
+	_, err = helpers.GenerateFavicons(newTestImage(10, 10), nil)
+	c.Assert(err, qt.ErrorMatches, "no favicon sizes given")
+	_, err = helpers.GenerateFavicons(newTestImage(10, 10), []int{0, 16})
+	c.Assert(err, qt.ErrorMatches, "favicon size must be between 1 and 256, got 0")
+	_, err = helpers.GenerateFavicons(newTestImage(10, 10), []int{16, -32})
+	c.Assert(err, qt.ErrorMatches, "favicon size must be between 1 and 256, got -32")
+	_, err = helpers.GenerateFavicons(newTestImage(10, 10), []int{16, 512})
+	c.Assert(err, qt.ErrorMatches, "favicon size must be between 1 and 256, got 512")
+}
@@ -0,0 +1,269 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// feedElement captures an element's name and text content. Elements are
+// collected in slices, as e.g. an RSS channel may have both a <link> and an
+// <atom:link> element.
+type feedElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+	Href    string `xml:"href,attr"`
+}
+
+type feedElements []feedElement
+
+// has reports whether there is a non-empty element without a namespace
+// prefix or in the given namespace.
+func (elements feedElements) has(namespace string) bool {
+	for _, e := range elements {
+		if e.XMLName.Space != "" && e.XMLName.Space != namespace {
+			continue
+		}
+		if strings.TrimSpace(e.Value) != "" || strings.TrimSpace(e.Href) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type rssFeed struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel *struct {
+		Title       feedElements `xml:"title"`
+		Link        feedElements `xml:"link"`
+		Description feedElements `xml:"description"`
+		Items       []struct {
+			Title       feedElements `xml:"title"`
+			Description feedElements `xml:"description"`
+			GUID        feedElements `xml:"guid"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	ID      feedElements `xml:"id"`
+	Title   feedElements `xml:"title"`
+	Updated feedElements `xml:"updated"`
+	Entries []struct {
+		ID      feedElements `xml:"id"`
+		Title   feedElements `xml:"title"`
+		Updated feedElements `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// ValidateFeed parses the RSS 2.0 or Atom feed in b, with format either "rss"
+// or "atom", and returns an error for every required element that is missing
+// or empty.
+//
+// For RSS, the channel must have a title, link and description, and every item
+// must have a guid and either a title or a description.
+// For Atom, both the feed and every entry must have an id, title and updated element.
+func ValidateFeed(b []byte, format string) []error {
+	var errs []error
+	missing := func(where, element string) {
+		errs = append(errs, fmt.Errorf("%s: %s is missing <%s>", format, where, element))
+	}
+
+	switch format {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(b, &feed); err != nil {
+			return []error{fmt.Errorf("rss: failed to parse feed: %w", err)}
+		}
+		if feed.Channel == nil {
+			missing("feed", "channel")
+			return errs
+		}
+		ch := feed.Channel
+		if !ch.Title.has("") {
+			missing("channel", "title")
+		}
+		if !ch.Link.has("") {
+			missing("channel", "link")
+		}
+		if !ch.Description.has("") {
+			missing("channel", "description")
+		}
+		for i, item := range ch.Items {
+			where := fmt.Sprintf("item %d", i+1)
+			if !item.Title.has("") && !item.Description.has("") {
+				missing(where, "title> or <description")
+			}
+			if !item.GUID.has("") {
+				missing(where, "guid")
+			}
+		}
+	case "atom":
+		var feed atomFeed
+		if err := xml.Unmarshal(b, &feed); err != nil {
+			return []error{fmt.Errorf("atom: failed to parse feed: %w", err)}
+		}
+		check := func(where string, id, title, updated feedElements) {
+			if !id.has(atomNamespace) {
+				missing(where, "id")
+			}
+			if !title.has(atomNamespace) {
+				missing(where, "title")
+			}
+			if !updated.has(atomNamespace) {
+				missing(where, "updated")
+			}
+		}
+		check("feed", feed.ID, feed.Title, feed.Updated)
+		for i, entry := range feed.Entries {
+			check(fmt.Sprintf("entry %d", i+1), entry.ID, entry.Title, entry.Updated)
+		}
+	default:
+		return []error{fmt.Errorf("unsupported feed format %q, use either rss or atom", format)}
+	}
+
+	return errs
+}
+
+// JSONFeedVersion is the version URL of the JSON Feed spec implemented by JSONFeed.
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// FeedMeta holds the top level metadata of a feed.
+type FeedMeta struct {
+	// Required.
+	Title string
+
+	HomePageURL string
+	FeedURL     string
+	Description string
+	Language    string
+	Icon        string
+	Favicon     string
+
+	AuthorName string
+	AuthorURL  string
+}
+
+// FeedItem is an item in a feed.
+type FeedItem struct {
+	// Required, unique and stable, e.g. the permalink.
+	ID string
+
+	URL     string
+	Title   string
+	Summary string
+	Image   string
+	Tags    []string
+
+	// At least one of ContentHTML and ContentText must be set.
+	ContentHTML string
+	ContentText string
+
+	DatePublished time.Time
+	DateModified  time.Time
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Favicon     string           `json:"favicon,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	ContentHTML   string   `json:"content_html,omitempty"`
+	ContentText   string   `json:"content_text,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// JSONFeed returns a JSON Feed 1.1 document, see https://www.jsonfeed.org/version/1.1/
+// Empty optional fields are omitted and the items keep their given order, so
+// the output is stable.
+func JSONFeed(meta FeedMeta, items []FeedItem) ([]byte, error) {
+	if meta.Title == "" {
+		return nil, errors.New("JSON feed: title must be set")
+	}
+
+	formatDate := func(d time.Time) string {
+		if d.IsZero() {
+			return ""
+		}
+		return d.Format(time.RFC3339)
+	}
+
+	feed := jsonFeed{
+		Version:     JSONFeedVersion,
+		Title:       meta.Title,
+		HomePageURL: meta.HomePageURL,
+		FeedURL:     meta.FeedURL,
+		Description: meta.Description,
+		Language:    meta.Language,
+		Icon:        meta.Icon,
+		Favicon:     meta.Favicon,
+		Items:       make([]jsonFeedItem, len(items)),
+	}
+
+	if meta.AuthorName != "" || meta.AuthorURL != "" {
+		feed.Authors = []jsonFeedAuthor{{Name: meta.AuthorName, URL: meta.AuthorURL}}
+	}
+
+	for i, item := range items {
+		if item.ID == "" {
+			return nil, fmt.Errorf("JSON feed: item %d: id must be set", i+1)
+		}
+		if item.ContentHTML == "" && item.ContentText == "" {
+			return nil, fmt.Errorf("JSON feed: item %q: either content_html or content_text must be set", item.ID)
+		}
+		feed.Items[i] = jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			ContentText:   item.ContentText,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: formatDate(item.DatePublished),
+			DateModified:  formatDate(item.DateModified),
+			Tags:          item.Tags,
+		}
+	}
+
+	return json.MarshalIndent(feed, "", "  ")
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestValidateFeed(t *testing.T) {
+	c := qt.New(t)
+
+	errorStrings := func(errs []error) []string {
+		var s []string
+		for _, err := range errs {
+			s = append(s, err.Error())
+		}
+		return s
+	}
+
+	c.Run("Valid RSS", func(c *qt.C) {
+		rss := `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>My Blog</title>
+    <link>https://example.org/</link>
+    <description>Recent content</description>
+    <atom:link href="https://example.org/index.xml" rel="self" type="application/rss+xml" />
+    <item>
+      <title>Post 1</title>
+      <link>https://example.org/p1/</link>
+      <guid>https://example.org/p1/</guid>
+    </item>
+  </channel>
+</rss>`
+		c.Assert(helpers.ValidateFeed([]byte(rss), "rss"), qt.HasLen, 0)
+	})
+
+	c.Run("RSS missing item guids", func(c *qt.C) {
+		rss := `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>My Blog</title>
+    <atom:link href="https://example.org/index.xml" rel="self" />
+    <description>Recent content</description>
+    <item><title>Post 1</title></item>
+    <item><title>Post 2</title><guid>https://example.org/p2/</guid></item>
+    <item><guid> </guid></item>
+  </channel>
+</rss>`
+		c.Assert(errorStrings(helpers.ValidateFeed([]byte(rss), "rss")), qt.DeepEquals, []string{
+			"rss: channel is missing <link>",
+			"rss: item 1 is missing <guid>",
+			"rss: item 3 is missing <title> or <description>",
+			"rss: item 3 is missing <guid>",
+		})
+	})
+
+	c.Run("Valid Atom", func(c *qt.C) {
+		atom := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:uuid:60a76c80-d399-11d9-b91C-0003939e0af6</id>
+  <title>My Blog</title>
+  <updated>2023-06-01T12:00:00Z</updated>
+  <entry>
+    <id>https://example.org/p1/</id>
+    <title type="html">Post 1</title>
+    <updated>2023-06-01T12:00:00Z</updated>
+  </entry>
+</feed>`
+		c.Assert(helpers.ValidateFeed([]byte(atom), "atom"), qt.HasLen, 0)
+	})
+
+	c.Run("Atom missing entry ids", func(c *qt.C) {
+		atom := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:uuid:60a76c80-d399-11d9-b91C-0003939e0af6</id>
+  <title>My Blog</title>
+  <entry>
+    <title>Post 1</title>
+    <updated>2023-06-01T12:00:00Z</updated>
+  </entry>
+  <entry>
+    <id>https://example.org/p2/</id>
+    <title>Post 2</title>
+    <updated>2023-06-01T12:00:00Z</updated>
+  </entry>
+</feed>`
+		c.Assert(errorStrings(helpers.ValidateFeed([]byte(atom), "atom")), qt.DeepEquals, []string{
+			"atom: feed is missing <updated>",
+			"atom: entry 1 is missing <id>",
+		})
+	})
+
+	c.Run("Invalid", func(c *qt.C) {
+		c.Assert(errorStrings(helpers.ValidateFeed([]byte("<rss><channel>"), "rss"))[0], qt.Contains, "failed to parse feed")
+		c.Assert(errorStrings(helpers.ValidateFeed([]byte("<rss></rss>"), "rss")), qt.DeepEquals, []string{"rss: feed is missing <channel>"})
+		c.Assert(errorStrings(helpers.ValidateFeed(nil, "json")), qt.DeepEquals, []string{`unsupported feed format "json", use either rss or atom`})
+	})
+}
+
+func TestJSONFeed(t *testing.T) {
+	c := qt.New(t)
+
+	meta := helpers.FeedMeta{
+		Title:       "My Blog",
+		HomePageURL: "https://example.org/",
+		FeedURL:     "https://example.org/feed.json",
+		AuthorName:  "Jane Doe",
+	}
+	items := []helpers.FeedItem{
+		{
+			ID:            "https://example.org/p1/",
+			URL:           "https://example.org/p1/",
+			Title:         "Post 1",
+			ContentHTML:   "<p>Hello</p>",
+			DatePublished: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+			Tags:          []string{"go", "hugo"},
+		},
+		{
+			ID:          "https://example.org/p2/",
+			ContentText: "Hello again",
+		},
+	}
+
+	b, err := helpers.JSONFeed(meta, items)
+	c.Assert(err, qt.IsNil)
+
+	var m map[string]any
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+	c.Assert(m["version"], qt.Equals, "https://jsonfeed.org/version/1.1")
+	c.Assert(m["title"], qt.Equals, "My Blog")
+	c.Assert(m["home_page_url"], qt.Equals, "https://example.org/")
+	c.Assert(m["authors"], qt.DeepEquals, []any{map[string]any{"name": "Jane Doe"}})
+	_, found := m["description"]
+	c.Assert(found, qt.IsFalse)
+
+	feedItems := m["items"].([]any)
+	c.Assert(feedItems, qt.HasLen, 2)
+	c.Assert(feedItems[0], qt.DeepEquals, map[string]any{
+		"id":             "https://example.org/p1/",
+		"url":            "https://example.org/p1/",
+		"title":          "Post 1",
+		"content_html":   "<p>Hello</p>",
+		"date_published": "2023-06-01T12:00:00Z",
+		"tags":           []any{"go", "hugo"},
+	})
+	c.Assert(feedItems[1], qt.DeepEquals, map[string]any{
+		"id":           "https://example.org/p2/",
+		"content_text": "Hello again",
+	})
+
+	// Deterministic.
+	b2, err := helpers.JSONFeed(meta, items)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b2), qt.Equals, string(b))
+
+	// No items is still a valid feed.
+	b, err = helpers.JSONFeed(meta, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Contains, `"items": []`)
+
+	_, err = helpers.JSONFeed(helpers.FeedMeta{}, nil)
+	c.Assert(err, qt.ErrorMatches, ".*title must be set")
+	_, err = helpers.JSONFeed(meta, []helpers.FeedItem{{ContentText: "a"}})
+	c.Assert(err, qt.ErrorMatches, ".*item 1: id must be set")
+	_, err = helpers.JSONFeed(meta, []helpers.FeedItem{{ID: "a"}})
+	c.Assert(err, qt.ErrorMatches, ".*either content_html or content_text must be set")
+}
@@ -0,0 +1,352 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/net/html"
+)
+
+// MeasureText returns the width and height in pixels of the bounding box of
+// text, a single line, when drawn with the TrueType or OpenType font in
+// fontData at the given size in points (at 72 DPI, so 1pt is 1px).
+func MeasureText(text string, fontData []byte, size float64) (width, height float64, err error) {
+	if size <= 0 {
+		return 0, 0, fmt.Errorf("font size must be positive, got %g", size)
+	}
+
+	f, err := opentype.Parse(fontData)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse font: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer face.Close()
+
+	bounds, _ := font.BoundString(face, text)
+
+	return fixedToFloat(bounds.Max.X - bounds.Min.X), fixedToFloat(bounds.Max.Y - bounds.Min.Y), nil
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// SubsetFont returns the TrueType font in fontData with the outlines of all
+// glyphs not needed to draw usedRunes removed, for smaller font files.
+// The .notdef glyph and the components of the used composite glyphs are kept.
+// The glyph IDs are unchanged, so the character map and metrics tables are
+// kept as is; the removed glyphs are left empty. The DSIG table, whose
+// signature would no longer match, is dropped.
+// Only fonts with TrueType outlines (a glyf table) are supported.
+func SubsetFont(fontData []byte, usedRunes []rune) ([]byte, error) {
+	f, err := sfnt.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	tables, err := readSFNTTables(fontData)
+	if err != nil {
+		return nil, err
+	}
+	head, loca, glyf, maxp := tables["head"], tables["loca"], tables["glyf"], tables["maxp"]
+	if glyf == nil || loca == nil {
+		return nil, errors.New("font subsetting is only supported for fonts with TrueType outlines")
+	}
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, errors.New("invalid font: head or maxp table too short")
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if longLoca {
+			if len(loca) < 4*(i+1) {
+				return nil, errors.New("invalid font: loca table too short")
+			}
+			offsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		} else {
+			if len(loca) < 2*(i+1) {
+				return nil, errors.New("invalid font: loca table too short")
+			}
+			offsets[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+	}
+	glyphData := func(gid int) ([]byte, error) {
+		start, end := offsets[gid], offsets[gid+1]
+		if start > end || end > len(glyf) {
+			return nil, fmt.Errorf("invalid font: glyph %d out of bounds", gid)
+		}
+		return glyf[start:end], nil
+	}
+
+	// Collect the glyphs to keep, including composite glyph components.
+	keep := map[int]bool{0: true}
+	var queue []int
+	var buf sfnt.Buffer
+	for _, r := range usedRunes {
+		gid, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			return nil, err
+		}
+		if gid != 0 && !keep[int(gid)] {
+			keep[int(gid)] = true
+			queue = append(queue, int(gid))
+		}
+	}
+	for len(queue) > 0 {
+		gid := queue[0]
+		queue = queue[1:]
+		data, err := glyphData(gid)
+		if err != nil {
+			return nil, err
+		}
+		components, err := compositeGlyphComponents(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid font: glyph %d: %w", gid, err)
+		}
+		for _, c := range components {
+			if c < numGlyphs && !keep[c] {
+				keep[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	// Build the new glyf and (long) loca tables.
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(newLoca[4*gid:], uint32(len(newGlyf)))
+		if !keep[gid] {
+			continue
+		}
+		data, err := glyphData(gid)
+		if err != nil {
+			return nil, err
+		}
+		newGlyf = append(newGlyf, data...)
+		for len(newGlyf)%4 != 0 {
+			newGlyf = append(newGlyf, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(len(newGlyf)))
+
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+
+	tables["glyf"] = newGlyf
+	tables["loca"] = newLoca
+	tables["head"] = newHead
+	delete(tables, "DSIG")
+
+	return writeSFNT(binary.BigEndian.Uint32(fontData), tables), nil
+}
+
+// readSFNTTables returns the tables in the SFNT font b keyed by tag.
+func readSFNTTables(b []byte) (map[string][]byte, error) {
+	if len(b) < 12 {
+		return nil, errors.New("invalid font: too short")
+	}
+	numTables := int(binary.BigEndian.Uint16(b[4:]))
+	if len(b) < 12+16*numTables {
+		return nil, errors.New("invalid font: table directory too short")
+	}
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		record := b[12+16*i:]
+		tag := string(record[:4])
+		offset := int(binary.BigEndian.Uint32(record[8:]))
+		length := int(binary.BigEndian.Uint32(record[12:]))
+		if offset < 0 || length < 0 || offset+length > len(b) {
+			return nil, fmt.Errorf("invalid font: table %q out of bounds", tag)
+		}
+		tables[tag] = b[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// writeSFNT writes an SFNT font with the given version and tables, and
+// updates the checksum adjustment in the head table.
+func writeSFNT(version uint32, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	header := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(header, version)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(16*numTables-searchRange))
+
+	out := header
+	headOffset := -1
+	for i, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			data = append([]byte(nil), data...)
+			binary.BigEndian.PutUint32(data[8:], 0)
+			headOffset = len(out)
+		}
+		record := out[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], sfntChecksum(data))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(data)))
+		out = append(out, data...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+
+	if headOffset != -1 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-sfntChecksum(out))
+	}
+
+	return out
+}
+
+func sfntChecksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// compositeGlyphComponents returns the glyph IDs of the components of the
+// glyph in data, or nil if it's a simple glyph.
+func compositeGlyphComponents(data []byte) ([]int, error) {
+	if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return nil, nil
+	}
+
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+
+	var components []int
+	for pos := 10; ; {
+		if pos+4 > len(data) {
+			return nil, errors.New("composite glyph too short")
+		}
+		flags := binary.BigEndian.Uint16(data[pos:])
+		components = append(components, int(binary.BigEndian.Uint16(data[pos+2:])))
+		pos += 4
+		if flags&argsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			pos += 2
+		case flags&haveXYScale != 0:
+			pos += 4
+		case flags&haveTwoByTwo != 0:
+			pos += 8
+		}
+		if flags&moreComponents == 0 {
+			return components, nil
+		}
+	}
+}
+
+// CollectUsedRunes returns the sorted set of runes in texts, e.g. to pass to
+// SubsetFont. Invalid UTF-8 is ignored.
+func CollectUsedRunes(texts ...[]byte) []rune {
+	seen := make(map[rune]bool)
+	for _, text := range texts {
+		addUsedRunes(seen, text)
+	}
+	return sortedRunes(seen)
+}
+
+// CollectUsedTextRunes is like CollectUsedRunes, but the texts are HTML
+// documents and only the runes in the text content are collected, leaving out
+// the markup and the content of script and style elements. Character
+// references are unescaped.
+func CollectUsedTextRunes(texts ...[]byte) []rune {
+	seen := make(map[rune]bool)
+	for _, text := range texts {
+		z := html.NewTokenizer(bytes.NewReader(text))
+		skip := false
+	tokens:
+		for {
+			switch z.Next() {
+			case html.ErrorToken:
+				break tokens
+			case html.StartTagToken:
+				name, _ := z.TagName()
+				skip = string(name) == "script" || string(name) == "style"
+			case html.EndTagToken:
+				skip = false
+			case html.TextToken:
+				if !skip {
+					addUsedRunes(seen, z.Text())
+				}
+			}
+		}
+	}
+	return sortedRunes(seen)
+}
+
+func addUsedRunes(seen map[rune]bool, b []byte) {
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r != utf8.RuneError || size > 1 {
+			seen[r] = true
+		}
+		b = b[size:]
+	}
+}
+
+func sortedRunes(seen map[rune]bool) []rune {
+	runes := make([]rune, 0, len(seen))
+	for r := range seen {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	return runes
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+	"golang.org/x/exp/slices"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestMeasureText(t *testing.T) {
+	c := qt.New(t)
+
+	w1, h1, err := helpers.MeasureText("Hugo", goregular.TTF, 32)
+	c.Assert(err, qt.IsNil)
+	c.Assert(w1 > 0 && h1 > 0, qt.IsTrue)
+
+	w2, h2, err := helpers.MeasureText("Hugo Rocks!", goregular.TTF, 32)
+	c.Assert(err, qt.IsNil)
+	c.Assert(w2 > w1, qt.IsTrue)
+	c.Assert(h2 < 40, qt.IsTrue)
+
+	// Twice the size is about twice as wide.
+	w3, _, err := helpers.MeasureText("Hugo", goregular.TTF, 64)
+	c.Assert(err, qt.IsNil)
+	c.Assert(w3/w1 > 1.9 && w3/w1 < 2.1, qt.IsTrue, qt.Commentf("%f %f", w1, w3))
+
+	w, h, err := helpers.MeasureText("", goregular.TTF, 32)
+	c.Assert(err, qt.IsNil)
+	c.Assert(w, qt.Equals, 0.0)
+	c.Assert(h, qt.Equals, 0.0)
+
+	_, _, err = helpers.MeasureText("Hugo", []byte("not a font"), 32)
+	c.Assert(err, qt.ErrorMatches, "failed to parse font.*")
+	_, _, err = helpers.MeasureText("Hugo", goregular.TTF, 0)
+	c.Assert(err, qt.ErrorMatches, "font size must be positive, got 0")
+}
+
+func TestSubsetFont(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := helpers.SubsetFont(goregular.TTF, []rune("Hugo Rocks! é"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(b) < len(goregular.TTF)/2, qt.IsTrue, qt.Commentf("%d %d", len(b), len(goregular.TTF)))
+
+	f, err := sfnt.Parse(b)
+	c.Assert(err, qt.IsNil)
+
+	var buf sfnt.Buffer
+	numSegments := func(r rune) int {
+		gid, err := f.GlyphIndex(&buf, r)
+		c.Assert(err, qt.IsNil)
+		c.Assert(gid, qt.Not(qt.Equals), sfnt.GlyphIndex(0))
+		segments, err := f.LoadGlyph(&buf, gid, fixed.I(32), nil)
+		c.Assert(err, qt.IsNil)
+		return len(segments)
+	}
+	for _, r := range "HugoRcks!é" {
+		c.Assert(numSegments(r) > 0, qt.IsTrue, qt.Commentf("%q", r))
+	}
+	for _, r := range "Zxq" {
+		c.Assert(numSegments(r), qt.Equals, 0, qt.Commentf("%q", r))
+	}
+
+	w1, _, err := helpers.MeasureText("Hugo", goregular.TTF, 32)
+	c.Assert(err, qt.IsNil)
+	w2, _, err := helpers.MeasureText("Hugo", b, 32)
+	c.Assert(err, qt.IsNil)
+	c.Assert(w2, qt.Equals, w1)
+
+	_, err = helpers.SubsetFont([]byte("not a font"), []rune("a"))
+	c.Assert(err, qt.ErrorMatches, "failed to parse font.*")
+}
+
+func TestCollectUsedRunes(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.CollectUsedRunes(), qt.HasLen, 0)
+	c.Assert(helpers.CollectUsedRunes([]byte("baba"), []byte("cab"), []byte("ø\xffa")), qt.DeepEquals, []rune("abcø"))
+
+	runes := helpers.CollectUsedRunes([]byte("Hugo Rocks!"), []byte("Hugo Rules!"))
+	c.Assert(string(runes), qt.Equals, " !HRcegklosu")
+	c.Assert(slices.IsSorted(runes), qt.IsTrue)
+
+	html := []byte(`<p class="xyz">ab &amp; <b>ba</b></p><script>var q;</script><style>p{}</style><em>c</em>`)
+	c.Assert(string(helpers.CollectUsedTextRunes(html, []byte("<p>dé</p>"))), qt.Equals, " &abcdé")
+	c.Assert(string(helpers.CollectUsedRunes(html)), qt.Not(qt.Equals), " &abcdé")
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gohugoio/hugo/common/maps"
+)
+
+// Freeze returns a read-only view of v if v is a map[string]any (or
+// maps.Params) or a []any; any other value is returned as is.
+// The view is a FrozenMap or a FrozenSlice, whose methods that would modify
+// the underlying data panic. Nested maps and slices are frozen on access.
+// The data is not copied, so the view reflects later changes made to v
+// directly.
+//
+// The views can be read in templates using their methods, e.g.
+// {{ $m.Get "key" }}, {{ range $m.Keys }} and {{ $s.Index 0 }}, and any
+// attempt to modify them, e.g. {{ $m.Set "key" "value" }}, panics.
+func Freeze(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		return FrozenMap{m: vv}
+	case maps.Params:
+		return FrozenMap{m: vv}
+	case []any:
+		return FrozenSlice{s: vv}
+	}
+	return v
+}
+
+// FrozenMap is a read-only view of a map, see Freeze.
+type FrozenMap struct {
+	m map[string]any
+}
+
+// Get returns the value for key, frozen, or nil if not found.
+func (m FrozenMap) Get(key string) any {
+	v, _ := m.Lookup(key)
+	return v
+}
+
+// Lookup returns the value for key, frozen, and whether it was found.
+func (m FrozenMap) Lookup(key string) (any, bool) {
+	v, found := m.m[key]
+	return Freeze(v), found
+}
+
+// Keys returns the sorted keys.
+func (m FrozenMap) Keys() []string {
+	keys := make([]string, 0, len(m.m))
+	for k := range m.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len returns the number of entries.
+func (m FrozenMap) Len() int {
+	return len(m.m)
+}
+
+// Set panics, as the map is frozen.
+// It returns a string so it can be called from templates, see maps.Scratch.
+func (m FrozenMap) Set(key string, v any) string {
+	panic(fmt.Sprintf("cannot set key %q: map is frozen", key))
+}
+
+// Delete panics, as the map is frozen.
+func (m FrozenMap) Delete(key string) string {
+	panic(fmt.Sprintf("cannot delete key %q: map is frozen", key))
+}
+
+// FrozenSlice is a read-only view of a slice, see Freeze.
+type FrozenSlice struct {
+	s []any
+}
+
+// Index returns the element at index i, frozen.
+// It panics if i is out of range.
+func (s FrozenSlice) Index(i int) any {
+	return Freeze(s.s[i])
+}
+
+// Len returns the number of elements.
+func (s FrozenSlice) Len() int {
+	return len(s.s)
+}
+
+// Set panics, as the slice is frozen.
+func (s FrozenSlice) Set(i int, v any) string {
+	panic(fmt.Sprintf("cannot set index %d: slice is frozen", i))
+}
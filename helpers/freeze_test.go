@@ -0,0 +1,117 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestFreeze(t *testing.T) {
+	c := qt.New(t)
+
+	data := map[string]any{
+		"title": "Hugo",
+		"author": maps.Params{
+			"name": "Bep",
+		},
+		"tags": []any{"a", map[string]any{"b": 1}},
+	}
+
+	frozen := helpers.Freeze(data)
+	m, ok := frozen.(helpers.FrozenMap)
+	c.Assert(ok, qt.IsTrue)
+
+	c.Assert(m.Len(), qt.Equals, 3)
+	c.Assert(m.Keys(), qt.DeepEquals, []string{"author", "tags", "title"})
+	c.Assert(m.Get("title"), qt.Equals, "Hugo")
+	c.Assert(m.Get("missing"), qt.IsNil)
+	_, found := m.Lookup("missing")
+	c.Assert(found, qt.IsFalse)
+
+	author := m.Get("author").(helpers.FrozenMap)
+	c.Assert(author.Get("name"), qt.Equals, "Bep")
+
+	tags := m.Get("tags").(helpers.FrozenSlice)
+	c.Assert(tags.Len(), qt.Equals, 2)
+	c.Assert(tags.Index(0), qt.Equals, "a")
+	c.Assert(tags.Index(1).(helpers.FrozenMap).Get("b"), qt.Equals, 1)
+
+	c.Assert(func() { m.Set("title", "Changed") }, qt.PanicMatches, `cannot set key "title": map is frozen`)
+	c.Assert(func() { m.Delete("title") }, qt.PanicMatches, `cannot delete key "title": map is frozen`)
+	c.Assert(func() { author.Set("name", "Changed") }, qt.PanicMatches, `cannot set key "name": map is frozen`)
+	c.Assert(func() { tags.Set(0, "Changed") }, qt.PanicMatches, `cannot set index 0: slice is frozen`)
+	c.Assert(data["title"], qt.Equals, "Hugo")
+
+	// The data is not copied.
+	data["title"] = "Changed"
+	c.Assert(m.Get("title"), qt.Equals, "Changed")
+	data["title"] = "Hugo"
+
+	// Writes through reflection, as done by e.g. template funcs, panic.
+	c.Assert(func() { reflect.ValueOf(m).SetMapIndex(reflect.ValueOf("title"), reflect.ValueOf("Changed")) }, qt.PanicMatches, `reflect: call of reflect.Value.SetMapIndex on struct Value`)
+	c.Assert(func() { reflect.ValueOf(tags).Index(0).Set(reflect.ValueOf("Changed")) }, qt.PanicMatches, `reflect: call of reflect.Value.Index on struct Value`)
+	c.Assert(data["title"], qt.Equals, "Hugo")
+	c.Assert(data["tags"].([]any)[0], qt.Equals, "a")
+
+	// Other values are returned as is.
+	c.Assert(helpers.Freeze("Hugo"), qt.Equals, "Hugo")
+	c.Assert(helpers.Freeze(nil), qt.IsNil)
+	c.Assert(helpers.Freeze([]string{"a"}), qt.DeepEquals, []string{"a"})
+}
+
+func TestFreezeTemplate(t *testing.T) {
+	c := qt.New(t)
+
+	data := helpers.Freeze(map[string]any{
+		"title": "Hugo",
+		"author": maps.Params{
+			"name": "Bep",
+		},
+		"tags": []any{"a", map[string]any{"b": 1}},
+	})
+
+	execute := func(s string) (string, error) {
+		tmpl, err := template.New("").Parse(s)
+		c.Assert(err, qt.IsNil)
+		var b strings.Builder
+		err = tmpl.Execute(&b, data)
+		return b.String(), err
+	}
+
+	result, err := execute(`{{ .Get "title" }}|{{ (.Get "author").Get "name" }}|{{ (.Get "tags").Index 0 }}|{{ ((.Get "tags").Index 1).Get "b" }}|{{ range .Keys }}{{ . }},{{ end }}|{{ .Len }}|{{ (.Get "tags").Len }}`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(result, qt.Equals, "Hugo|Bep|a|1|author,tags,title,|3|2")
+
+	// The underlying map can't be reached, e.g. by index.
+	_, err = execute(`{{ index . "title" }}`)
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	_, err = execute(`{{ .Set "title" "Changed" }}`)
+	c.Assert(err, qt.ErrorMatches, `.*cannot set key "title": map is frozen`)
+	_, err = execute(`{{ (.Get "author").Set "name" "Changed" }}`)
+	c.Assert(err, qt.ErrorMatches, `.*cannot set key "name": map is frozen`)
+	_, err = execute(`{{ .Delete "title" }}`)
+	c.Assert(err, qt.ErrorMatches, `.*cannot delete key "title": map is frozen`)
+	_, err = execute(`{{ ((.Get "tags").Index 1).Set "b" 2 }}`)
+	c.Assert(err, qt.ErrorMatches, `.*cannot set key "b": map is frozen`)
+	_, err = execute(`{{ (.Get "tags").Set 0 "Changed" }}`)
+	c.Assert(err, qt.ErrorMatches, `.*cannot set index 0: slice is frozen`)
+}
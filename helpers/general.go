@@ -18,23 +18,33 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/tabwriter"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/gohugoio/hugo/common/loggers"
 
 	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/common/types"
 
 	"github.com/spf13/afero"
 
 	"github.com/jdkato/prose/transform"
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
 
 	bp "github.com/gohugoio/hugo/bufferpool"
 	"github.com/spf13/pflag"
@@ -43,19 +53,81 @@ import (
 // FilePathSeparator as defined by os.Separator.
 const FilePathSeparator = string(filepath.Separator)
 
+// caseInsensitivePaths is whether the default file systems on this OS are
+// case insensitive.
+var caseInsensitivePaths = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// NormalizePath converts both forward and back slashes in p to
+// FilePathSeparator and cleans the result, see filepath.Clean.
+func NormalizePath(p string) string {
+	p = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return filepath.Separator
+		}
+		return r
+	}, p)
+	return filepath.Clean(p)
+}
+
+// PathsEqual reports whether a and b are the same path after NormalizePath.
+// The comparison is case insensitive on Windows and macOS.
+func PathsEqual(a, b string) bool {
+	return PathsEqualCase(a, b, caseInsensitivePaths)
+}
+
+// PathsEqualCase is like PathsEqual, but with caseInsensitive deciding
+// whether to compare using Unicode case folding.
+func PathsEqualCase(a, b string, caseInsensitive bool) bool {
+	a, b = NormalizePath(a), NormalizePath(b)
+	if caseInsensitive {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
 // TCPListen starts listening on a valid TCP port.
 func TCPListen() (net.Listener, *net.TCPAddr, error) {
-	l, err := net.Listen("tcp", ":0")
+	return TCPListenPreferred(0)
+}
+
+// TCPListenPreferred starts listening on the given TCP port, falling back to
+// a random port only if that port is already in use. Any other error, e.g.
+// an invalid port, is returned. A port of 0 always means a random port. Callers can compare the returned address' Port with port to
+// tell whether the fallback was used.
+func TCPListenPreferred(port int) (net.Listener, *net.TCPAddr, error) {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil && port != 0 && isAddrInUse(err) {
+		l, err = net.Listen("tcp", ":0")
+	}
 	if err != nil {
 		return nil, nil, err
 	}
+	return tcpListenerAddr(l)
+}
+
+// ListenerFromFD returns a listener for the already open TCP socket with the
+// given file descriptor, e.g. one inherited from systemd socket activation.
+// The listener uses a duplicate of fd, and fd itself is closed.
+func ListenerFromFD(fd uintptr) (net.Listener, *net.TCPAddr, error) {
+	f := os.NewFile(fd, fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file descriptor %d is not a listening socket: %w", fd, err)
+	}
+	return tcpListenerAddr(l)
+}
+
+func tcpListenerAddr(l net.Listener) (net.Listener, *net.TCPAddr, error) {
 	addr := l.Addr()
 	if a, ok := addr.(*net.TCPAddr); ok {
 		return l, a, nil
 	}
 	l.Close()
 	return nil, nil, fmt.Errorf("unable to obtain a valid tcp port: %v", addr)
-
 }
 
 // InStringArray checks if a string is an element of a slice of strings
@@ -69,72 +141,113 @@ func InStringArray(arr []string, el string) bool {
 	return false
 }
 
+// InSortedStringArray is like InStringArray, but uses a binary search.
+// The slice must be sorted in increasing order, see UniqueStringsSorted,
+// otherwise the result is undefined.
+func InSortedStringArray(arr []string, el string) bool {
+	i := sort.SearchStrings(arr, el)
+	return i < len(arr) && arr[i] == el
+}
+
 // FirstUpper returns a string with the first character as upper case.
 func FirstUpper(s string) string {
 	if s == "" {
 		return ""
 	}
+	if c := s[0]; c < utf8.RuneSelf {
+		// Fast path for ASCII.
+		if 'a' <= c && c <= 'z' {
+			return string(c-'a'+'A') + s[1:]
+		}
+		return s
+	}
 	r, n := utf8.DecodeRuneInString(s)
 	return string(unicode.ToUpper(r)) + s[n:]
 }
 
-// UniqueStrings returns a new slice with any duplicates removed.
-func UniqueStrings(s []string) []string {
-	unique := make([]string, 0, len(s))
-	for i, val := range s {
-		var seen bool
-		for j := 0; j < i; j++ {
-			if s[j] == val {
-				seen = true
-				break
-			}
-		}
-		if !seen {
-			unique = append(unique, val)
+// uniqueMapThreshold is the slice length above which Unique uses a map to
+// track the seen values instead of scanning the values kept so far.
+// The map allocation dominates for short slices; see BenchmarkUniqueScanVsMap
+// for where the crossover is.
+const uniqueMapThreshold = 128
+
+// Unique returns a new slice with any duplicates removed, keeping the first
+// occurrence of every value in order.
+func Unique[T comparable](s []T) []T {
+	return uniqueInto(make([]T, 0, len(s)), s)
+}
+
+// UniqueSorted returns a sorted slice with any duplicates removed.
+// It will modify the input slice.
+func UniqueSorted[T constraints.Ordered](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	slices.Sort(s)
+	i := 0
+	for j := 1; j < len(s); j++ {
+		if s[i] == s[j] {
+			continue
 		}
+		i++
+		s[i] = s[j]
 	}
-	return unique
+
+	return s[:i+1]
 }
 
-// UniqueStringsReuse returns a slice with any duplicates removed.
-// It will modify the input slice.
-func UniqueStringsReuse(s []string) []string {
-	result := s[:0]
-	for i, val := range s {
-		var seen bool
+// uniqueInto appends the unique values in s to dst, which may share its
+// backing array with s as long as it starts at the same position.
+func uniqueInto[T comparable](dst, s []T) []T {
+	if len(s) > uniqueMapThreshold {
+		return uniqueMap(dst, s)
+	}
+	return uniqueScan(dst, s)
+}
 
-		for j := 0; j < i; j++ {
-			if s[j] == val {
+func uniqueScan[T comparable](dst, s []T) []T {
+	for _, val := range s {
+		var seen bool
+		for _, kept := range dst {
+			if kept == val {
 				seen = true
 				break
 			}
 		}
-
 		if !seen {
-			result = append(result, val)
+			dst = append(dst, val)
 		}
 	}
-	return result
+	return dst
 }
 
-// UniqueStringsReuse returns a sorted slice with any duplicates removed.
-// It will modify the input slice.
-func UniqueStringsSorted(s []string) []string {
-	if len(s) == 0 {
-		return nil
-	}
-	ss := sort.StringSlice(s)
-	ss.Sort()
-	i := 0
-	for j := 1; j < len(s); j++ {
-		if !ss.Less(i, j) {
+func uniqueMap[T comparable](dst, s []T) []T {
+	seen := make(map[T]struct{}, len(s))
+	for _, val := range s {
+		if _, found := seen[val]; found {
 			continue
 		}
-		i++
-		s[i] = s[j]
+		seen[val] = struct{}{}
+		dst = append(dst, val)
 	}
+	return dst
+}
 
-	return s[:i+1]
+// UniqueStrings returns a new slice with any duplicates removed.
+func UniqueStrings(s []string) []string {
+	return Unique(s)
+}
+
+// UniqueStringsReuse returns a slice with any duplicates removed.
+// It will modify the input slice.
+func UniqueStringsReuse(s []string) []string {
+	return uniqueInto(s[:0], s)
+}
+
+// UniqueStringsReuse returns a sorted slice with any duplicates removed.
+// It will modify the input slice.
+func UniqueStringsSorted(s []string) []string {
+	return UniqueSorted(s)
 }
 
 // ReaderToBytes takes an io.Reader argument, reads from it
@@ -164,8 +277,116 @@ func ReaderToString(lines io.Reader) string {
 	return b.String()
 }
 
+// ToStringSlice converts v, typically a front matter value, to a string slice.
+// A scalar value is wrapped in a slice, and the elements of a slice or array
+// are converted to strings, with any nil elements dropped.
+// A nil v returns nil. The result never shares memory with v.
+func ToStringSlice(v any) []string {
+	switch vv := v.(type) {
+	case nil:
+		return nil
+	case []string:
+		result := make([]string, len(vv))
+		copy(result, vv)
+		return result
+	case []any:
+		result := make([]string, 0, len(vv))
+		for _, e := range vv {
+			if e != nil {
+				result = append(result, stringifyValue(e))
+			}
+		}
+		return result
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		result := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			e := rv.Index(i)
+			if (e.Kind() == reflect.Interface || e.Kind() == reflect.Pointer) && e.IsNil() {
+				continue
+			}
+			result = append(result, stringifyValue(e.Interface()))
+		}
+		return result
+	default:
+		return []string{stringifyValue(v)}
+	}
+}
+
+func stringifyValue(v any) string {
+	if s, err := types.ToStringE(v); err == nil {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
 // ReaderContains reports whether subslice is within r.
 func ReaderContains(r io.Reader, subslice []byte) bool {
+	return readerContains(r, subslice, bytes.Contains)
+}
+
+// ReaderContainsFold is like ReaderContains, but the match is ASCII
+// case-insensitive.
+func ReaderContainsFold(r io.Reader, subslice []byte) bool {
+	lower := make([]byte, len(subslice))
+	for i, c := range subslice {
+		lower[i] = toLowerASCII(c)
+	}
+	return readerContains(r, lower, containsFoldASCII)
+}
+
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// containsFoldASCII reports whether lowerSubslice, which must be ASCII lower case,
+// is within b under ASCII case folding.
+func containsFoldASCII(b, lowerSubslice []byte) bool {
+	n := len(lowerSubslice)
+	for i := 0; i+n <= len(b); i++ {
+		match := true
+		for j := 0; j < n; j++ {
+			if toLowerASCII(b[i+j]) != lowerSubslice[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// PeekBytes reads up to n bytes from r. It returns the bytes read and a
+// reader that yields those bytes followed by the rest of r, so the caller can
+// sniff the start of a stream and still consume all of it.
+// Fewer than n bytes are returned only if r is exhausted, which is not an
+// error. Any other read error is returned.
+func PeekBytes(r io.Reader, n int) ([]byte, io.Reader, error) {
+	if n <= 0 {
+		return nil, r, nil
+	}
+	b := make([]byte, n)
+	k, err := io.ReadFull(r, b)
+	b = b[:k]
+	switch err {
+	case nil:
+		return b, io.MultiReader(bytes.NewReader(b), r), nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return b, bytes.NewReader(b), nil
+	default:
+		return b, nil, err
+	}
+}
+
+func readerContains(r io.Reader, subslice []byte, contains func(b, subslice []byte) bool) bool {
 	if r == nil || len(subslice) == 0 {
 		return false
 	}
@@ -188,7 +409,7 @@ func ReaderContains(r io.Reader, subslice []byte) bool {
 			n, err = io.ReadAtLeast(r, buff[halflen:], halflen)
 		}
 
-		if n > 0 && bytes.Contains(buff, subslice) {
+		if n > 0 && contains(buff, subslice) {
 			return true
 		}
 
@@ -199,6 +420,81 @@ func ReaderContains(r io.Reader, subslice []byte) bool {
 	return false
 }
 
+// countChunkSize is the number of bytes CountLines and CountWords read at a time.
+const countChunkSize = 32 * 1024
+
+// CountLines returns the number of lines in r, counting a last line without a
+// trailing newline. Both \n and \r\n line endings are supported.
+func CountLines(r io.Reader) (int, error) {
+	var lines int
+	endsWithNewline := true
+	err := readChunks(r, func(b []byte) {
+		lines += bytes.Count(b, []byte{'\n'})
+		endsWithNewline = b[len(b)-1] == '\n'
+	})
+	if !endsWithNewline {
+		lines++
+	}
+	return lines, err
+}
+
+// CountWords returns the number of words in r, words being separated by
+// Unicode whitespace (see unicode.IsSpace).
+// Note that this under-counts text in languages not using spaces between
+// words, e.g. Chinese and Japanese.
+func CountWords(r io.Reader) (int, error) {
+	var words int
+	inWord := false
+	// Any incomplete UTF-8 sequence at the end of the previous chunk.
+	var pending []byte
+	err := readChunks(r, func(b []byte) {
+		if len(pending) > 0 {
+			pending = append(pending, b...)
+			b = pending
+		}
+		for len(b) > 0 {
+			if !utf8.FullRune(b) {
+				break
+			}
+			r, size := utf8.DecodeRune(b)
+			b = b[size:]
+			if unicode.IsSpace(r) {
+				inWord = false
+			} else if !inWord {
+				inWord = true
+				words++
+			}
+		}
+		pending = append(pending[:0:0], b...)
+	})
+	if len(pending) > 0 && !inWord {
+		// Invalid UTF-8 at the end.
+		words++
+	}
+	return words, err
+}
+
+// readChunks reads r in chunks of countChunkSize bytes, passing every
+// non-empty chunk to fn.
+func readChunks(r io.Reader, fn func(b []byte)) error {
+	buf := bp.GetBuffer()
+	defer bp.PutBuffer(buf)
+
+	for {
+		buf.Reset()
+		n, err := io.CopyN(buf, r, countChunkSize)
+		if n > 0 {
+			fn(buf.Bytes())
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 // GetTitleFunc returns a func that can be used to transform a string to
 // title case.
 //
@@ -207,19 +503,68 @@ func ReaderContains(r io.Reader, subslice []byte) bool {
 // - "Go" (strings.Title)
 // - "AP" (see https://www.apstylebook.com/)
 // - "Chicago" (see http://www.chicagomanualofstyle.org/home.html)
+// - "Sentence" (only the first letter is made upper case, see FirstUpper)
+// - "Lower" (strings.ToLower)
 //
 // If an unknown or empty style is provided, AP style is what you get.
 func GetTitleFunc(style string) func(s string) string {
-	switch strings.ToLower(style) {
-	case "go":
-		return strings.Title
-	case "chicago":
-		tc := transform.NewTitleConverter(transform.ChicagoStyle)
-		return tc.Title
-	default:
-		tc := transform.NewTitleConverter(transform.APStyle)
-		return tc.Title
+	return getTitleCaser(style).title
+}
+
+// TitleCaser transforms strings to title case in a given style.
+type TitleCaser interface {
+	// Title returns s in title case.
+	Title(s string) string
+
+	// Style returns the resolved style, one of "Go", "AP", "Chicago",
+	// "Sentence" or "Lower".
+	Style() string
+}
+
+// NewTitleCaser returns a TitleCaser for the given style, see GetTitleFunc
+// for the supported styles.
+// If an unknown or empty style is provided, AP style is what you get.
+func NewTitleCaser(style string) TitleCaser {
+	return getTitleCaser(style)
+}
+
+var (
+	titleCasersInit sync.Once
+	titleCasers     map[string]*titleCaser
+)
+
+// getTitleCaser returns the shared titleCaser for style.
+// The title converters are stateless, so they're created once and reused.
+func getTitleCaser(style string) *titleCaser {
+	titleCasersInit.Do(func() {
+		titleCasers = map[string]*titleCaser{
+			"go":       {style: "Go", title: strings.Title},
+			"ap":       {style: "AP", title: transform.NewTitleConverter(transform.APStyle).Title},
+			"chicago":  {style: "Chicago", title: transform.NewTitleConverter(transform.ChicagoStyle).Title},
+			"sentence": {style: "Sentence", title: FirstUpper},
+			"lower":    {style: "Lower", title: strings.ToLower},
+		}
+	})
+	if tc, found := titleCasers[style]; found {
+		return tc
 	}
+	if tc, found := titleCasers[strings.ToLower(style)]; found {
+		return tc
+	}
+	return titleCasers["ap"]
+}
+
+type titleCaser struct {
+	style string
+	title func(s string) string
+}
+
+func (t *titleCaser) Title(s string) string {
+	return t.title(s)
+}
+
+func (t *titleCaser) Style() string {
+	return t.style
 }
 
 // HasStringsPrefix tests whether the string slice s begins with prefix slice s.
@@ -232,6 +577,28 @@ func HasStringsSuffix(s, suffix []string) bool {
 	return len(s) >= len(suffix) && compareStringSlices(s[len(s)-len(suffix):], suffix)
 }
 
+// HasAnyStringsPrefix returns the index of the first of prefixes that the
+// string slice s begins with, and whether one was found.
+func HasAnyStringsPrefix(s []string, prefixes [][]string) (int, bool) {
+	for i, prefix := range prefixes {
+		if len(s) >= len(prefix) && compareStringSlices(s[0:len(prefix)], prefix) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// HasAnyStringsSuffix returns the index of the first of suffixes that the
+// string slice s ends with, and whether one was found.
+func HasAnyStringsSuffix(s []string, suffixes [][]string) (int, bool) {
+	for i, suffix := range suffixes {
+		if len(s) >= len(suffix) && compareStringSlices(s[len(s)-len(suffix):], suffix) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
 func compareStringSlices(a, b []string) bool {
 	if a == nil && b == nil {
 		return true
@@ -259,6 +626,18 @@ type DistinctLogger struct {
 	loggers.Logger
 	sync.RWMutex
 	m map[string]bool
+
+	// If max > 0, at most max log statements are remembered, the oldest
+	// evicted first.
+	max  int
+	keys []string // Ring buffer of the remembered keys when max > 0.
+	next int      // Index in keys of the next key to evict.
+
+	// If counting is set, counts holds the number of attempts to log each
+	// remembered statement, keyed as in m. counting is set on creation and
+	// never changed, so it can be read without holding the lock.
+	counting bool
+	counts   map[string]int
 }
 
 func (l *DistinctLogger) Reset() {
@@ -266,6 +645,31 @@ func (l *DistinctLogger) Reset() {
 	defer l.Unlock()
 
 	l.m = make(map[string]bool)
+	l.keys = l.keys[:0]
+	l.next = 0
+	if l.counting {
+		l.counts = make(map[string]int)
+	}
+}
+
+// Summary returns the number of times each log statement was attempted
+// logged, including the first time, since the last Reset.
+// A statement evicted because of the limit is forgotten along with its count.
+// The keys are on the form "<level>: <statement>", e.g. "warnf: some warning",
+// so the same statement logged at different levels is counted separately.
+// It returns nil if the logger wasn't created with counting enabled,
+// see NewCountingDistinctLogger.
+func (l *DistinctLogger) Summary() map[string]int {
+	l.RLock()
+	defer l.RUnlock()
+	if !l.counting {
+		return nil
+	}
+	summary := make(map[string]int, len(l.counts))
+	for k, v := range l.counts {
+		summary[k] = v
+	}
+	return summary
 }
 
 // Println will log the string returned from fmt.Sprintln given the arguments,
@@ -351,17 +755,44 @@ func (l *DistinctLogger) hasPrinted(key string) bool {
 }
 
 func (l *DistinctLogger) printIfNotPrinted(level, logStatement string, print func()) {
-	key := level + logStatement
-	if l.hasPrinted(key) {
+	key := level + ": " + logStatement
+	if !l.counting && l.hasPrinted(key) {
 		return
 	}
 	l.Lock()
 	defer l.Unlock()
-	l.m[key] = true // Placing this after print() can cause duplicate warning entries to be logged when --panicOnWarning is true.
+	if l.counting {
+		l.counts[key]++
+	}
+	if l.m[key] {
+		return
+	}
+	l.remember(key) // Placing this after print() can cause duplicate warning entries to be logged when --panicOnWarning is true.
 	print()
 
 }
 
+// remember marks key as printed, evicting the oldest key, and its count,
+// if needed. l must be locked.
+func (l *DistinctLogger) remember(key string) {
+	if l.max <= 0 {
+		l.m[key] = true
+		return
+	}
+	if l.m[key] {
+		return
+	}
+	if len(l.keys) < l.max {
+		l.keys = append(l.keys, key)
+	} else {
+		delete(l.m, l.keys[l.next])
+		delete(l.counts, l.keys[l.next])
+		l.keys[l.next] = key
+		l.next = (l.next + 1) % l.max
+	}
+	l.m[key] = true
+}
+
 // NewDistinctErrorLogger creates a new DistinctLogger that logs ERRORs
 func NewDistinctErrorLogger() loggers.Logger {
 	return &DistinctLogger{m: make(map[string]bool), Logger: loggers.NewErrorLogger()}
@@ -372,6 +803,22 @@ func NewDistinctLogger(logger loggers.Logger) loggers.Logger {
 	return &DistinctLogger{m: make(map[string]bool), Logger: logger}
 }
 
+// NewDistinctLoggerWithLimit creates a new DistinctLogger that logs to the
+// provided logger and remembers at most max log statements, evicting the
+// oldest first. An evicted statement will be logged again if repeated.
+// A max <= 0 means no limit.
+func NewDistinctLoggerWithLimit(logger loggers.Logger, max int) loggers.Logger {
+	return &DistinctLogger{m: make(map[string]bool), Logger: logger, max: max}
+}
+
+// NewCountingDistinctLogger creates a new DistinctLogger that logs to the
+// provided logger and counts the attempts to log each statement, see Summary.
+// It remembers, and counts, at most max log statements, see
+// NewDistinctLoggerWithLimit.
+func NewCountingDistinctLogger(logger loggers.Logger, max int) loggers.Logger {
+	return &DistinctLogger{m: make(map[string]bool), Logger: logger, max: max, counting: true, counts: make(map[string]int)}
+}
+
 // NewDistinctWarnLogger creates a new DistinctLogger that logs WARNs
 func NewDistinctWarnLogger() loggers.Logger {
 	return &DistinctLogger{m: make(map[string]bool), Logger: loggers.NewWarningLogger()}
@@ -397,31 +844,59 @@ func InitLoggers() {
 // The idea is two remove an item in two Hugo releases to give users and theme authors
 // plenty of time to fix their templates.
 func Deprecated(item, alternative string, err bool) {
+	DeprecatedWithContext(item, alternative, err, "")
+}
+
+// DeprecatedWithContext is like Deprecated, but the message is prefixed with ctx,
+// typically the template name and line where the deprecated item was used.
+// The same deprecation is reported once for every distinct ctx.
+func DeprecatedWithContext(item, alternative string, err bool, ctx string) {
+	var prefix string
+	if ctx != "" {
+		prefix = ctx + ": "
+	}
 	if err {
-		DistinctErrorLog.Errorf("%s is deprecated and will be removed in Hugo %s. %s", item, hugo.CurrentVersion.Next().ReleaseVersion(), alternative)
+		DistinctErrorLog.Errorf("%s%s is deprecated and will be removed in Hugo %s. %s", prefix, item, hugo.CurrentVersion.Next().ReleaseVersion(), alternative)
 	} else {
 		var warnPanicMessage string
 		if !loggers.PanicOnWarning.Load() {
 			warnPanicMessage = "\n\nRe-run Hugo with the flag --panicOnWarning to get a better error message."
 		}
-		DistinctWarnLog.Warnf("%s is deprecated and will be removed in a future release. %s%s", item, alternative, warnPanicMessage)
+		DistinctWarnLog.Warnf("%s%s is deprecated and will be removed in a future release. %s%s", prefix, item, alternative, warnPanicMessage)
 	}
 }
 
-// SliceToLower goes through the source slice and lowers all values.
-func SliceToLower(s []string) []string {
+// SliceApply returns a new slice with fn applied to all values in s.
+// A nil s returns nil.
+func SliceApply(s []string, fn func(string) string) []string {
 	if s == nil {
 		return nil
 	}
 
 	l := make([]string, len(s))
 	for i, v := range s {
-		l[i] = strings.ToLower(v)
+		l[i] = fn(v)
 	}
 
 	return l
 }
 
+// SliceToLower goes through the source slice and lowers all values.
+func SliceToLower(s []string) []string {
+	return SliceApply(s, strings.ToLower)
+}
+
+// SliceToUpper goes through the source slice and uppers all values.
+func SliceToUpper(s []string) []string {
+	return SliceApply(s, strings.ToUpper)
+}
+
+// SliceTrimSpace goes through the source slice and trims leading and trailing
+// white space from all values.
+func SliceTrimSpace(s []string) []string {
+	return SliceApply(s, strings.TrimSpace)
+}
+
 // MD5String takes a string and returns its MD5 hash.
 func MD5String(f string) string {
 	h := md5.New()
@@ -429,10 +904,143 @@ func MD5String(f string) string {
 	return hex.EncodeToString(h.Sum([]byte{}))
 }
 
+// StableContentHash returns the MD5 hash of b with all matches of
+// volatilePatterns masked out, e.g. a build timestamp comment, so only
+// meaningful content changes affect the hash.
+// Every match is replaced with the same placeholder, so removing or
+// adding a volatile section still changes the hash.
+func StableContentHash(b []byte, volatilePatterns []*regexp.Regexp) string {
+	placeholder := []byte{0}
+	for _, re := range volatilePatterns {
+		b = re.ReplaceAllLiteral(b, placeholder)
+	}
+	h := md5.New()
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFs returns a fingerprint of all the files below root in fs, built from
+// each file's path relative to root, its size and its MD5FromFileFast hash,
+// in StableFileList order. Any error walking or reading the files is returned.
+func HashFs(fs afero.Fs, root string) (string, error) {
+	filenames, err := StableFileList(fs, root)
+	if err != nil {
+		return "", err
+	}
+
+	h := md5.New()
+	for _, filename := range filenames {
+		fileHash, size, err := hashFileFast(fs, filepath.Join(root, filepath.FromSlash(filename)))
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %q: %w", filename, err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%s\n", filename, size, fileHash)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFileFast(fs afero.Fs, filename string) (string, int64, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	hash, err := MD5FromFileFast(f)
+	return hash, fi.Size(), err
+}
+
+// StableFileList returns the paths of all the regular files below root,
+// relative to root and using forward slashes, sorted in byte order.
+// The order doesn't depend on the locale, the OS or the walk order,
+// which makes it suitable for creating reproducible archives.
+func StableFileList(fs afero.Fs, root string) ([]string, error) {
+	var filenames []string
+	err := afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk %q: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		filenames = append(filenames, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+// VerifyManifest compares the files below root in fs with manifest, which
+// maps file paths relative to root, using forward slashes, to the expected
+// MD5 hash of their content, as created by MD5FromReader.
+// It returns the sorted paths in manifest missing in fs, the paths in fs
+// not in manifest, and the paths with a different hash.
+func VerifyManifest(fs afero.Fs, root string, manifest map[string]string) (missing, extra, changed []string, err error) {
+	filenames, err := StableFileList(fs, root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	seen := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		seen[filename] = true
+		expected, found := manifest[filename]
+		if !found {
+			extra = append(extra, filename)
+			continue
+		}
+		hash, err := md5FromFile(fs, filepath.Join(root, filepath.FromSlash(filename)))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to hash %q: %w", filename, err)
+		}
+		if !strings.EqualFold(hash, expected) {
+			changed = append(changed, filename)
+		}
+	}
+
+	for filename := range manifest {
+		if !seen[filename] {
+			missing = append(missing, filename)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing, extra, changed, nil
+}
+
+func md5FromFile(fs afero.Fs, filename string) (string, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return MD5FromReader(f)
+}
+
 // MD5FromFileFast creates a MD5 hash from the given file. It only reads parts of
 // the file for speed, so don't use it if the files are very subtly different.
 // It will not close the file.
 func MD5FromFileFast(r io.ReadSeeker) (string, error) {
+	return HashFromFileFast(r, md5.New())
+}
+
+// HashFromFileFast is MD5FromFileFast with the hash algorithm of choice, e.g.
+// sha256.New(). It reads the same parts of the file as MD5FromFileFast, but
+// note that a different algorithm gives a different hash, so switching
+// algorithms changes all fingerprints created with it.
+// It will not close the file.
+func HashFromFileFast(r io.ReadSeeker, h hash.Hash) (string, error) {
 	const (
 		// Do not change once set in stone!
 		maxChunks = 8
@@ -440,7 +1048,6 @@ func MD5FromFileFast(r io.ReadSeeker) (string, error) {
 		seek      = 2048
 	)
 
-	h := md5.New()
 	buff := make([]byte, peekSize)
 
 	for i := 0; i < maxChunks; i++ {
@@ -470,26 +1077,62 @@ func MD5FromFileFast(r io.ReadSeeker) (string, error) {
 
 // MD5FromReader creates a MD5 hash from the given reader.
 func MD5FromReader(r io.Reader) (string, error) {
-	h := md5.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return "", nil
+	digest, _, err := HashAndSize(r, md5.New())
+	return digest, err
+}
+
+// HashAndSize reads all of r into h and returns the hex encoded digest and
+// the number of bytes read.
+func HashAndSize(r io.Reader, h hash.Hash) (digest string, size int64, err error) {
+	size, err = io.Copy(h, r)
+	if err != nil {
+		return "", size, err
 	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return hex.EncodeToString(h.Sum(nil)), size, nil
 }
 
 // IsWhitespace determines if the given rune is whitespace.
+// Only ASCII whitespace is considered, see IsWhitespaceUnicode.
 func IsWhitespace(r rune) bool {
 	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
 }
 
+// IsWhitespaceUnicode determines if the given rune is Unicode whitespace, as
+// defined by unicode.IsSpace (which includes e.g. the no-break space U+00A0 and
+// the ideographic space U+3000), or one of the invisible separators
+// often found in copy-pasted text: the zero width space U+200B, the word
+// joiner U+2060, the Mongolian vowel separator U+180E and the zero width
+// no-break space (byte order mark) U+FEFF.
+func IsWhitespaceUnicode(r rune) bool {
+	switch r {
+	case '\u180e', '\u200b', '\u2060', '\ufeff':
+		return true
+	}
+	return unicode.IsSpace(r)
+}
+
+// legacyHugoFlags maps lower case legacy command-line flag names to their
+// canonical name. All canonical names must be flags defined in commands.
+var legacyHugoFlags = map[string]string{
+	"baseurl": "baseURL",
+	"notimes": "noTimes",
+}
+
+// LegacyHugoFlags returns a copy of the legacy to canonical flag name
+// mappings used by NormalizeHugoFlags. The legacy names are lower case.
+func LegacyHugoFlags() map[string]string {
+	m := make(map[string]string, len(legacyHugoFlags))
+	for k, v := range legacyHugoFlags {
+		m[k] = v
+	}
+	return m
+}
+
 // NormalizeHugoFlags facilitates transitions of Hugo command-line flags,
-// e.g. --baseUrl to --baseURL, --uglyUrls to --uglyURLs
+// e.g. --baseUrl to --baseURL, --notimes to --noTimes.
 func NormalizeHugoFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
-	switch name {
-	case "baseUrl":
-		name = "baseURL"
-	case "uglyUrls":
-		name = "uglyURLs"
+	if canonical, found := legacyHugoFlags[strings.ToLower(name)]; found {
+		name = canonical
 	}
 	return pflag.NormalizedName(name)
 }
@@ -506,3 +1149,109 @@ func PrintFs(fs afero.Fs, path string, w io.Writer) {
 		return nil
 	})
 }
+
+// PrintFsOptions configures PrintFsDetailed.
+type PrintFsOptions struct {
+	// Which columns to print in addition to the path.
+	Mode    bool
+	Size    bool
+	ModTime bool
+
+	// One of "name", "size" or "modtime" (oldest first). Any other value
+	// means the walk order, which is lexical within each directory.
+	SortBy string
+}
+
+// PrintFsDetailed prints the given filesystem to w starting from the given
+// path, with more details about each file if set in opts.
+// An error reading a file or directory is printed in place of its details,
+// and the walk continues.
+// Without sorting, each entry is written as it's visited and the columns are
+// not aligned. Sorting requires the full walk, and the columns are aligned.
+func PrintFsDetailed(fs afero.Fs, path string, w io.Writer, opts PrintFsOptions) {
+	if fs == nil {
+		return
+	}
+
+	type entry struct {
+		path string
+		info os.FileInfo
+		err  error
+	}
+
+	columns := func(e entry) []string {
+		var columns []string
+		if e.err != nil {
+			columns = append(columns, fmt.Sprintf("error: %s", e.err))
+		} else if e.info != nil {
+			if opts.Mode {
+				columns = append(columns, e.info.Mode().String())
+			}
+			if opts.Size {
+				columns = append(columns, strconv.FormatInt(e.info.Size(), 10))
+			}
+			if opts.ModTime {
+				columns = append(columns, e.info.ModTime().Format(time.RFC3339))
+			}
+		}
+		return columns
+	}
+
+	var less func(a, b entry) bool
+	switch opts.SortBy {
+	case "name":
+		less = func(a, b entry) bool {
+			return a.path < b.path
+		}
+	case "size", "modtime":
+		sortKey := func(e entry) (int64, bool) {
+			if e.info == nil {
+				return 0, false
+			}
+			if opts.SortBy == "size" {
+				return e.info.Size(), true
+			}
+			return e.info.ModTime().UnixNano(), true
+		}
+		// Entries that could not be read last.
+		less = func(a, b entry) bool {
+			ka, oka := sortKey(a)
+			kb, okb := sortKey(b)
+			if oka != okb {
+				return oka
+			}
+			return ka < kb
+		}
+	}
+
+	if less == nil {
+		afero.Walk(fs, path, func(path string, info os.FileInfo, err error) error {
+			if columns := columns(entry{path: path, info: info, err: err}); len(columns) > 0 {
+				fmt.Fprintf(w, "%s  %s\n", path, strings.Join(columns, "  "))
+			} else {
+				fmt.Fprintln(w, path)
+			}
+			return nil
+		})
+		return
+	}
+
+	var entries []entry
+	afero.Walk(fs, path, func(path string, info os.FileInfo, err error) error {
+		entries = append(entries, entry{path: path, info: info, err: err})
+		return nil
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		if columns := columns(e); len(columns) > 0 {
+			fmt.Fprintf(tw, "%s\t%s\n", e.path, strings.Join(columns, "\t"))
+		} else {
+			fmt.Fprintln(tw, e.path)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/loggers"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func TestDistinctLoggerWithLimit(t *testing.T) {
+	c := qt.New(t)
+
+	logger := loggers.NewBasicLoggerForWriter(jww.LevelWarn, io.Discard)
+	l := NewDistinctLoggerWithLimit(logger, 10).(*DistinctLogger)
+	warnCount := func() int {
+		return int(logger.LogCounters().WarnCounter.Count())
+	}
+
+	for i := 0; i < 100; i++ {
+		l.Warnf("warning %d", i)
+		l.Warnf("warning %d", i)
+		c.Assert(len(l.m) <= 10, qt.IsTrue)
+	}
+	c.Assert(l.m, qt.HasLen, 10)
+	c.Assert(l.keys, qt.HasLen, 10)
+	c.Assert(warnCount(), qt.Equals, 100)
+
+	// The most recent are still remembered.
+	l.Warnf("warning %d", 99)
+	l.Warnf("warning %d", 90)
+	c.Assert(warnCount(), qt.Equals, 100)
+
+	// The oldest have been evicted.
+	l.Warnf("warning %d", 0)
+	c.Assert(warnCount(), qt.Equals, 101)
+	c.Assert(l.m, qt.HasLen, 10)
+
+	l.Reset()
+	c.Assert(l.m, qt.HasLen, 0)
+	l.Warnf("warning %d", 99)
+	c.Assert(warnCount(), qt.Equals, 102)
+
+	// Counts are evicted along with the statements.
+	l = NewCountingDistinctLogger(logger, 10).(*DistinctLogger)
+	for i := 0; i < 100; i++ {
+		l.Warnf("warning %d", i)
+		l.Warnf("warning %d", i)
+		c.Assert(len(l.counts) <= 10, qt.IsTrue)
+	}
+	summary := l.Summary()
+	c.Assert(summary, qt.HasLen, 10)
+	c.Assert(summary["warnf: warning 99"], qt.Equals, 2)
+	_, found := summary["warnf: warning 0"]
+	c.Assert(found, qt.IsFalse)
+
+	// No limit.
+	l = NewDistinctLogger(logger).(*DistinctLogger)
+	for i := 0; i < 100; i++ {
+		l.Warnf("warning %d", i)
+	}
+	c.Assert(l.m, qt.HasLen, 100)
+	c.Assert(l.keys, qt.HasLen, 0)
+}
+
+// BenchmarkUniqueScanVsMap is used to decide uniqueMapThreshold.
+func BenchmarkUniqueScanVsMap(b *testing.B) {
+	for _, size := range []int{8, 16, 32, 64, 128, 256, 1024} {
+		// Every value appears twice.
+		input := make([]int, size)
+		for i := range input {
+			input[i] = i / 2
+		}
+		dst := make([]int, 0, size)
+
+		b.Run(fmt.Sprintf("Scan/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if len(uniqueScan(dst[:0], input)) != size/2 {
+					b.Fatal("invalid count")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Map/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if len(uniqueMap(dst[:0], input)) != size/2 {
+					b.Fatal("invalid count")
+				}
+			}
+		})
+	}
+}
@@ -14,17 +14,35 @@
 package helpers_test
 
 import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gohugoio/hugo/common/loggers"
 	"github.com/gohugoio/hugo/helpers"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/pflag"
 )
 
 func TestResolveMarkup(t *testing.T) {
@@ -55,6 +73,67 @@ func TestResolveMarkup(t *testing.T) {
 	}
 }
 
+func TestNormalizeHugoFlags(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name   string
+		expect string
+	}{
+		{"baseUrl", "baseURL"},
+		{"baseurl", "baseURL"},
+		{"BaseUrl", "baseURL"},
+		{"baseURL", "baseURL"},
+		{"noTimes", "noTimes"},
+		{"notimes", "noTimes"},
+		{"NoTimes", "noTimes"},
+		{"buildDrafts", "buildDrafts"},
+		{"unknownFlag", "unknownFlag"},
+	} {
+		c.Assert(helpers.NormalizeHugoFlags(nil, test.name), qt.Equals, pflag.NormalizedName(test.expect), qt.Commentf(test.name))
+	}
+}
+
+func TestDistinctLoggerSummary(t *testing.T) {
+	c := qt.New(t)
+
+	logger := loggers.NewBasicLoggerForWriter(jww.LevelWarn, io.Discard)
+	l := helpers.NewCountingDistinctLogger(logger, 0).(*helpers.DistinctLogger)
+
+	const n = 412
+	for i := 0; i < n; i++ {
+		l.Warnf("deprecated %s", "foo")
+	}
+	l.Warnln("once")
+	l.Errorln("deprecated foo")
+
+	// The warn counter also counts errors.
+	c.Assert(logger.LogCounters().WarnCounter.Count(), qt.Equals, uint64(3))
+	c.Assert(l.Summary(), qt.DeepEquals, map[string]int{"warnf: deprecated foo": n, "warnln: once": 1, "errorln: deprecated foo": 1})
+
+	l.Reset()
+	c.Assert(l.Summary(), qt.HasLen, 0)
+
+	c.Assert(helpers.NewDistinctLogger(logger).(*helpers.DistinctLogger).Summary(), qt.IsNil)
+
+	// Concurrent logging, Reset and Summary.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Warnf("warning %d", j%10)
+				if i == 0 && j%20 == 0 {
+					l.Reset()
+				}
+				l.Summary()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
 func TestDistinctLoggerDoesNotLockOnWarningPanic(t *testing.T) {
 	// Testing to make sure logger mutex doesn't lock if warnings cause panics.
 	// func Warnf() of DistinctLogger is defined in general.go
@@ -109,6 +188,84 @@ func TestDistinctLoggerDoesNotLockOnWarningPanic(t *testing.T) {
 	}
 }
 
+func TestNormalizePath(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"content/posts/a.md", "content/posts/a.md"},
+		{`content\posts\a.md`, "content/posts/a.md"},
+		{`content/posts\a.md`, "content/posts/a.md"},
+		{`/content//posts/./a.md`, "/content/posts/a.md"},
+		{`content\docs\..\posts\a.md`, "content/posts/a.md"},
+		{"content/posts/", "content/posts"},
+		{"../a/./b/..", "../a"},
+		{"", "."},
+	} {
+		c.Assert(helpers.NormalizePath(test.in), qt.Equals, filepath.FromSlash(test.expect), qt.Commentf(test.in))
+	}
+}
+
+func TestPathsEqual(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		a, b            string
+		caseInsensitive bool
+		expect          bool
+	}{
+		{"content/posts/a.md", `content\posts\a.md`, false, true},
+		{"content/posts/a.md", `content/docs/../posts/./a.md`, false, true},
+		{"/themes/mytheme/layouts", `\themes\mytheme\layouts\`, false, true},
+		{"content/posts/a.md", "content/posts/b.md", false, false},
+		{"content/posts/a.md", "Content/Posts/A.md", false, false},
+		{"content/posts/a.md", `Content\Posts\A.md`, true, true},
+		{"content/posts/\u00e5.md", "content/posts/\u00c5.md", true, true},
+		{"content/posts/a.md", "content/posts/b.md", true, false},
+	} {
+		c.Assert(helpers.PathsEqualCase(test.a, test.b, test.caseInsensitive), qt.Equals, test.expect, qt.Commentf("%s %s", test.a, test.b))
+	}
+
+	c.Assert(helpers.PathsEqual("content/posts/a.md", `content\posts\a.md`), qt.IsTrue)
+	c.Assert(helpers.PathsEqual("content/posts/a.md", "content/posts/b.md"), qt.IsFalse)
+	caseInsensitive := runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+	c.Assert(helpers.PathsEqual("content/a.md", "CONTENT/A.md"), qt.Equals, caseInsensitive)
+}
+
+func TestInSortedStringArray(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.InSortedStringArray(nil, ""), qt.IsFalse)
+	c.Assert(helpers.InSortedStringArray([]string{""}, ""), qt.IsTrue)
+
+	r := rand.New(rand.NewSource(32))
+	randString := func() string {
+		b := make([]byte, r.Intn(3))
+		for i := range b {
+			b[i] = byte('a' + r.Intn(4))
+		}
+		return string(b)
+	}
+
+	for i := 0; i < 500; i++ {
+		arr := make([]string, r.Intn(20))
+		for j := range arr {
+			arr[j] = randString()
+		}
+		if r.Intn(2) == 0 {
+			arr = helpers.UniqueStringsSorted(arr)
+		} else {
+			sort.Strings(arr)
+		}
+		for j := 0; j < 10; j++ {
+			el := randString()
+			c.Assert(helpers.InSortedStringArray(arr, el), qt.Equals, helpers.InStringArray(arr, el), qt.Commentf("%q in %q", el, arr))
+		}
+	}
+}
+
 func TestFirstUpper(t *testing.T) {
 	for i, this := range []struct {
 		in     string
@@ -119,12 +276,47 @@ func TestFirstUpper(t *testing.T) {
 		{"Foo Bar", "Foo Bar"},
 		{"", ""},
 		{"å", "Å"},
+		{"a", "A"},
+		{"z", "Z"},
+		{"1st", "1st"},
+		{" foo", " foo"},
+		{"_foo", "_foo"},
+		{"{foo", "{foo"},
+		{"`foo", "`foo"},
+		{"ǆ", "Ǆ"},
+		{"ßtraße", "ßtraße"},
+		{"\xfffoo", "\ufffdfoo"},
 	} {
 		result := helpers.FirstUpper(this.in)
 		if result != this.expect {
 			t.Errorf("[%d] got %s but expected %s", i, result, this.expect)
 		}
 	}
+
+	// The ASCII fast path must behave as the general Unicode path.
+	firstUpperUnicode := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		r, n := utf8.DecodeRuneInString(s)
+		return string(unicode.ToUpper(r)) + s[n:]
+	}
+	for c := 0; c < 256; c++ {
+		s := string([]byte{byte(c)}) + "foo"
+		if got, expect := helpers.FirstUpper(s), firstUpperUnicode(s); got != expect {
+			t.Errorf("[%d] got %q but expected %q", c, got, expect)
+		}
+	}
+}
+
+func BenchmarkFirstUpper(b *testing.B) {
+	for _, s := range []string{"somewhere over the rainbow", "Somewhere over the rainbow", "åpent hav"} {
+		b.Run(s, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				helpers.FirstUpper(s)
+			}
+		})
+	}
 }
 
 func TestHasStringsPrefix(t *testing.T) {
@@ -166,6 +358,62 @@ func TestHasStringsSuffix(t *testing.T) {
 	}
 }
 
+func TestHasAnyStringsPrefix(t *testing.T) {
+	prefixes := [][]string{
+		{"blog", "2023"},
+		{"blog"},
+		{"docs", "api"},
+		{"docs"},
+	}
+	for i, this := range []struct {
+		s        []string
+		prefixes [][]string
+		expect   int
+	}{
+		{[]string{"blog", "2023", "post"}, prefixes, 0},
+		{[]string{"blog", "2022", "post"}, prefixes, 1},
+		{[]string{"blog"}, prefixes, 1},
+		{[]string{"docs", "api", "x"}, prefixes, 2},
+		{[]string{"docs", "guide"}, prefixes, 3},
+		{[]string{"about"}, prefixes, -1},
+		{[]string{}, prefixes, -1},
+		// First match wins.
+		{[]string{"blog", "2023", "post"}, [][]string{{"blog"}, {"blog", "2023"}}, 0},
+		{[]string{"a"}, nil, -1},
+	} {
+		idx, found := helpers.HasAnyStringsPrefix(this.s, this.prefixes)
+		if idx != this.expect || found != (this.expect != -1) {
+			t.Fatalf("[%d] got %d, %t but expected %d", i, idx, found, this.expect)
+		}
+	}
+}
+
+func TestHasAnyStringsSuffix(t *testing.T) {
+	suffixes := [][]string{
+		{"2023", "index"},
+		{"index"},
+		{"list"},
+	}
+	for i, this := range []struct {
+		s        []string
+		suffixes [][]string
+		expect   int
+	}{
+		{[]string{"blog", "2023", "index"}, suffixes, 0},
+		{[]string{"blog", "2022", "index"}, suffixes, 1},
+		{[]string{"tags", "list"}, suffixes, 2},
+		{[]string{"index", "blog"}, suffixes, -1},
+		// First match wins.
+		{[]string{"blog", "2023", "index"}, [][]string{{"index"}, {"2023", "index"}}, 0},
+		{[]string{"a"}, nil, -1},
+	} {
+		idx, found := helpers.HasAnyStringsSuffix(this.s, this.suffixes)
+		if idx != this.expect || found != (this.expect != -1) {
+			t.Fatalf("[%d] got %d, %t but expected %d", i, idx, found, this.expect)
+		}
+	}
+}
+
 var containsTestText = (`На берегу пустынных волн
 Стоял он, дум великих полн,
 И вдаль глядел. Пред ним широко
@@ -245,6 +493,50 @@ func TestSliceToLower(t *testing.T) {
 	}
 }
 
+func TestSliceToUpperAndTrimSpace(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.SliceToUpper([]string{"a", "B", "ø"}), qt.DeepEquals, []string{"A", "B", "Ø"})
+	c.Assert(helpers.SliceTrimSpace([]string{" a", "b \n", "\tc d "}), qt.DeepEquals, []string{"a", "b", "c d"})
+	c.Assert(helpers.SliceApply([]string{"a", "b"}, helpers.FirstUpper), qt.DeepEquals, []string{"A", "B"})
+
+	c.Assert(helpers.SliceToUpper(nil), qt.IsNil)
+	c.Assert(helpers.SliceTrimSpace(nil), qt.IsNil)
+	c.Assert(helpers.SliceToLower(nil), qt.IsNil)
+	c.Assert(helpers.SliceApply([]string{}, strings.ToUpper), qt.DeepEquals, []string{})
+
+	// The source slice is not modified.
+	in := []string{" A "}
+	c.Assert(helpers.SliceTrimSpace(in), qt.DeepEquals, []string{"A"})
+	c.Assert(in, qt.DeepEquals, []string{" A "})
+}
+
+func TestTCPListenPreferred(t *testing.T) {
+	c := qt.New(t)
+
+	l1, addr1, err := helpers.TCPListen()
+	c.Assert(err, qt.IsNil)
+	c.Assert(addr1.Port, qt.Not(qt.Equals), 0)
+
+	// The port is taken, so we get another one.
+	l2, addr2, err := helpers.TCPListenPreferred(addr1.Port)
+	c.Assert(err, qt.IsNil)
+	c.Assert(addr2.Port, qt.Not(qt.Equals), addr1.Port)
+	c.Assert(l2.Close(), qt.IsNil)
+
+	// The port is free, so we get it.
+	c.Assert(l1.Close(), qt.IsNil)
+	l3, addr3, err := helpers.TCPListenPreferred(addr1.Port)
+	c.Assert(err, qt.IsNil)
+	c.Assert(addr3.Port, qt.Equals, addr1.Port)
+	c.Assert(l3.Close(), qt.IsNil)
+	// Other errors are returned.
+	for _, port := range []int{-1, 70000} {
+		_, _, err := helpers.TCPListenPreferred(port)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf("port %d", port))
+	}
+}
+
 func TestReaderContains(t *testing.T) {
 	c := qt.New(t)
 	for i, this := range append(containsBenchTestData, containsAdditionalTestData...) {
@@ -258,6 +550,71 @@ func TestReaderContains(t *testing.T) {
 	c.Assert(helpers.ReaderContains(nil, nil), qt.Equals, false)
 }
 
+func TestPeekBytes(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		input  string
+		n      int
+		expect string
+	}{
+		{"---\ntitle: foo\n---\ncontent", 3, "---"},
+		{"+++", 3, "+++"},
+		{"#!", 3, "#!"},
+		{"", 3, ""},
+		{"abc", 0, ""},
+		{"abc", -1, ""},
+	} {
+		for _, r := range []io.Reader{strings.NewReader(test.input), iotest.OneByteReader(strings.NewReader(test.input))} {
+			b, rr, err := helpers.PeekBytes(r, test.n)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(b), qt.Equals, test.expect, qt.Commentf(test.input))
+			all, err := io.ReadAll(rr)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(all), qt.Equals, test.input, qt.Commentf(test.input))
+		}
+	}
+
+	myErr := errors.New("read failed")
+	_, _, err := helpers.PeekBytes(iotest.ErrReader(myErr), 3)
+	c.Assert(err, qt.Equals, myErr)
+
+	// Errors after the peeked bytes are left to the caller.
+	b, rr, err := helpers.PeekBytes(io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(myErr)), 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "abc")
+	_, err = io.ReadAll(rr)
+	c.Assert(err, qt.Equals, myErr)
+}
+
+func TestReaderContainsFold(t *testing.T) {
+	c := qt.New(t)
+	for i, this := range append(containsBenchTestData, containsAdditionalTestData...) {
+		result := helpers.ReaderContainsFold(strings.NewReader(this.v1), this.v2)
+		if result != this.expect {
+			t.Errorf("[%d] got %t but expected %t", i, result, this.expect)
+		}
+	}
+
+	c.Assert(helpers.ReaderContainsFold(strings.NewReader(`<p>Hugo</p><SCRIPT src="a.js">`), []byte("<script")), qt.IsTrue)
+	c.Assert(helpers.ReaderContainsFold(strings.NewReader(`<p>Hugo</p><script src="a.js">`), []byte("<ScRiPt")), qt.IsTrue)
+	c.Assert(helpers.ReaderContainsFold(strings.NewReader(`<p>Hugo</p><style>`), []byte("<script")), qt.IsFalse)
+	c.Assert(helpers.ReaderContainsFold(strings.NewReader("Ünïcode"), []byte("üNÏCODE")), qt.IsFalse)
+	c.Assert(helpers.ReaderContainsFold(nil, []byte("a")), qt.IsFalse)
+	c.Assert(helpers.ReaderContainsFold(strings.NewReader("a"), nil), qt.IsFalse)
+
+	// The needle is 8 bytes, so the buffer is read 16 bytes at a time.
+	// Place the match across and around each of the half-buffer boundaries.
+	needle := []byte("MarkerXY")
+	for _, boundary := range []int{16, 32, 48, 64} {
+		for split := 0; split <= len(needle); split++ {
+			content := strings.Repeat("-", boundary-split) + "mArKeRxy" + strings.Repeat("-", 40)
+			c.Assert(helpers.ReaderContainsFold(strings.NewReader(content), needle), qt.IsTrue, qt.Commentf("boundary %d split %d", boundary, split))
+			c.Assert(helpers.ReaderContains(strings.NewReader(content), []byte("mArKeRxy")), qt.IsTrue, qt.Commentf("boundary %d split %d", boundary, split))
+		}
+	}
+}
+
 func TestGetTitleFunc(t *testing.T) {
 	title := "somewhere over the rainbow"
 	c := qt.New(t)
@@ -269,6 +626,44 @@ func TestGetTitleFunc(t *testing.T) {
 	c.Assert(helpers.GetTitleFunc("ap")(title), qt.Equals, "Somewhere Over the Rainbow")
 	c.Assert(helpers.GetTitleFunc("")(title), qt.Equals, "Somewhere Over the Rainbow")
 	c.Assert(helpers.GetTitleFunc("unknown")(title), qt.Equals, "Somewhere Over the Rainbow")
+	c.Assert(helpers.GetTitleFunc("sentence")(title), qt.Equals, "Somewhere over the rainbow")
+	c.Assert(helpers.GetTitleFunc("Sentence")("hugo and the GoHugo API"), qt.Equals, "Hugo and the GoHugo API")
+	c.Assert(helpers.GetTitleFunc("sentence")(""), qt.Equals, "")
+	c.Assert(helpers.GetTitleFunc("lower")("Somewhere Over The RAINBOW"), qt.Equals, title)
+	c.Assert(helpers.GetTitleFunc("Lower")(title), qt.Equals, title)
+}
+
+func BenchmarkGetTitleFunc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		helpers.GetTitleFunc("ap")
+	}
+}
+
+func TestNewTitleCaser(t *testing.T) {
+	title := "somewhere over the rainbow"
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		style         string
+		expectStyle   string
+		expectedTitle string
+	}{
+		{"go", "Go", "Somewhere Over The Rainbow"},
+		{"Chicago", "Chicago", "Somewhere over the Rainbow"},
+		{"AP", "AP", "Somewhere Over the Rainbow"},
+		{"sentence", "Sentence", "Somewhere over the rainbow"},
+		{"LOWER", "Lower", "somewhere over the rainbow"},
+		{"", "AP", "Somewhere Over the Rainbow"},
+		{"unknown", "AP", "Somewhere Over the Rainbow"},
+	} {
+		tc := helpers.NewTitleCaser(test.style)
+		c.Assert(tc.Style(), qt.Equals, test.expectStyle, qt.Commentf(test.style))
+		c.Assert(tc.Title(title), qt.Equals, test.expectedTitle, qt.Commentf(test.style))
+		c.Assert(helpers.GetTitleFunc(test.style)(title), qt.Equals, test.expectedTitle, qt.Commentf(test.style))
+		// The converters are shared.
+		c.Assert(helpers.NewTitleCaser(test.style), qt.Equals, tc, qt.Commentf(test.style))
+	}
 }
 
 func BenchmarkReaderContains(b *testing.B) {
@@ -283,6 +678,30 @@ func BenchmarkReaderContains(b *testing.B) {
 	}
 }
 
+func TestToStringSlice(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.ToStringSlice(nil), qt.IsNil)
+	c.Assert(helpers.ToStringSlice("a"), qt.DeepEquals, []string{"a"})
+	c.Assert(helpers.ToStringSlice(""), qt.DeepEquals, []string{""})
+	c.Assert(helpers.ToStringSlice(42), qt.DeepEquals, []string{"42"})
+	c.Assert(helpers.ToStringSlice(true), qt.DeepEquals, []string{"true"})
+	c.Assert(helpers.ToStringSlice(template.HTML("<b>a</b>")), qt.DeepEquals, []string{"<b>a</b>"})
+	c.Assert(helpers.ToStringSlice([]string{"a", "b"}), qt.DeepEquals, []string{"a", "b"})
+	c.Assert(helpers.ToStringSlice([]any{"a", nil, 1, 2.5, false, nil}), qt.DeepEquals, []string{"a", "1", "2.5", "false"})
+	c.Assert(helpers.ToStringSlice([]any{}), qt.DeepEquals, []string{})
+	c.Assert(helpers.ToStringSlice([]int{1, 2}), qt.DeepEquals, []string{"1", "2"})
+	c.Assert(helpers.ToStringSlice([2]string{"a", "b"}), qt.DeepEquals, []string{"a", "b"})
+	c.Assert(helpers.ToStringSlice([]any{map[string]any{"a": 1}}), qt.DeepEquals, []string{"map[a:1]"})
+
+	// The result is a copy.
+	in := []string{"a", "b"}
+	out := helpers.ToStringSlice(in)
+	out[0] = "c"
+	c.Assert(in, qt.DeepEquals, []string{"a", "b"})
+	c.Assert(helpers.ToStringSlice([]string{}), qt.DeepEquals, []string{})
+}
+
 func TestUniqueStrings(t *testing.T) {
 	in := []string{"a", "b", "a", "b", "c", "", "a", "", "d"}
 	output := helpers.UniqueStrings(in)
@@ -310,6 +729,40 @@ func TestUniqueStringsSorted(t *testing.T) {
 	c.Assert(helpers.UniqueStringsSorted(nil), qt.IsNil)
 }
 
+func TestUnique(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.Unique([]int{3, 1, 3, 2, 1, 4}), qt.DeepEquals, []int{3, 1, 2, 4})
+	c.Assert(helpers.Unique([]int64{}), qt.DeepEquals, []int64{})
+
+	type id string
+	c.Assert(helpers.Unique([]id{"b", "a", "b"}), qt.DeepEquals, []id{"b", "a"})
+
+	// Large enough to use a map.
+	var in, expected []int
+	for i := 0; i < 1000; i++ {
+		in = append(in, 99-i%100)
+	}
+	for i := 99; i >= 0; i-- {
+		expected = append(expected, i)
+	}
+	c.Assert(helpers.Unique(in), qt.DeepEquals, expected)
+
+	reused := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		reused = append(reused, fmt.Sprint(i%100))
+	}
+	c.Assert(helpers.UniqueStringsReuse(reused), qt.HasLen, 100)
+}
+
+func TestUniqueSorted(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.UniqueSorted([]int{3, 1, 3, 2, 1, 4}), qt.DeepEquals, []int{1, 2, 3, 4})
+	c.Assert(helpers.UniqueSorted([]float64{2.5, -1, 2.5}), qt.DeepEquals, []float64{-1, 2.5})
+	c.Assert(helpers.UniqueSorted([]int(nil)), qt.IsNil)
+}
+
 func TestFastMD5FromFile(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
@@ -362,11 +815,70 @@ func TestFastMD5FromFile(t *testing.T) {
 	c.Assert(err, qt.IsNil)
 	c.Assert(m4, qt.Not(qt.Equals), m3)
 
+	_, err = bf2.Seek(0, io.SeekStart)
+	c.Assert(err, qt.IsNil)
 	m5, err := helpers.MD5FromReader(bf2)
 	c.Assert(err, qt.IsNil)
 	c.Assert(m5, qt.Not(qt.Equals), m4)
 }
 
+func TestStableContentHash(t *testing.T) {
+	c := qt.New(t)
+
+	volatile := []*regexp.Regexp{
+		regexp.MustCompile(`<!-- Generated at [^>]* -->`),
+		regexp.MustCompile(`data-build-id="[^"]*"`),
+	}
+
+	page := func(ts, buildID, content string) []byte {
+		return []byte(fmt.Sprintf(`<html data-build-id="%s"><!-- Generated at %s --><p>%s</p></html>`, buildID, ts, content))
+	}
+
+	h1 := helpers.StableContentHash(page("2023-06-01T12:00:00Z", "abc", "Hello"), volatile)
+	h2 := helpers.StableContentHash(page("2023-06-02T08:30:00Z", "def", "Hello"), volatile)
+	h3 := helpers.StableContentHash(page("2023-06-01T12:00:00Z", "abc", "Hello!"), volatile)
+
+	c.Assert(h1, qt.Equals, h2)
+	c.Assert(h1, qt.Not(qt.Equals), h3)
+	c.Assert(h1, qt.HasLen, 32)
+
+	// Removing the volatile section is a change.
+	c.Assert(helpers.StableContentHash([]byte(`<p>Hello</p>`), volatile), qt.Not(qt.Equals), helpers.StableContentHash([]byte(`<!-- Generated at now --><p>Hello</p>`), volatile))
+
+	// No patterns is a plain MD5 hash.
+	c.Assert(helpers.StableContentHash([]byte("Hello"), nil), qt.Equals, helpers.MD5String("Hello"))
+}
+
+func TestHashFromFileFast(t *testing.T) {
+	c := qt.New(t)
+
+	// These must never change, see MD5FromFileFast.
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"", "3b5d3c7d207e37dceeedd301e35e2e58"},
+		{"Hugo Rocks!", "3223d67ad8a17489f7fe1af84df96b0a"},
+		{strings.Repeat("0123456789", 30), "bd3ba0e6b5b821bb5f9a92452c1446af"},
+		{strings.Repeat("abcdefghij", 1000), "0737e17276a34c2da4bf47f108ba38fd"},
+	} {
+		h, err := helpers.MD5FromFileFast(strings.NewReader(test.in))
+		c.Assert(err, qt.IsNil)
+		c.Assert(h, qt.Equals, test.expect)
+
+		h, err = helpers.HashFromFileFast(strings.NewReader(test.in), md5.New())
+		c.Assert(err, qt.IsNil)
+		c.Assert(h, qt.Equals, test.expect)
+	}
+
+	h1, err := helpers.HashFromFileFast(strings.NewReader("Hugo Rocks!"), sha256.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(h1, qt.HasLen, 64)
+	h2, err := helpers.HashFromFileFast(strings.NewReader("Hugo Rocks!"), sha256.New())
+	c.Assert(err, qt.IsNil)
+	c.Assert(h2, qt.Equals, h1)
+}
+
 func BenchmarkMD5FromFileFast(b *testing.B) {
 	fs := afero.NewMemMapFs()
 
@@ -447,3 +959,412 @@ func BenchmarkUniqueStrings(b *testing.B) {
 		}
 	})
 }
+
+type statErrorFs struct {
+	afero.Fs
+	failPath string
+}
+
+func (fs statErrorFs) Stat(name string) (os.FileInfo, error) {
+	if name == fs.failPath {
+		return nil, errors.New("stat failed")
+	}
+	return fs.Fs.Stat(name)
+}
+
+type failingOpenFs struct {
+	afero.Fs
+	filename string
+}
+
+func (fs failingOpenFs) Open(name string) (afero.File, error) {
+	if name == fs.filename {
+		return nil, os.ErrPermission
+	}
+	return fs.Fs.Open(name)
+}
+
+func TestHashFs(t *testing.T) {
+	c := qt.New(t)
+
+	files := map[string]string{
+		"index.html":          "<html>home</html>",
+		"posts/a/index.html":  "<html>a</html>",
+		"posts/b/index.html":  "<html>b</html>",
+		"css/styles.css":      "body { color: red; }",
+		"images/empty.png":    "",
+		"posts/index.xml":     "<rss></rss>",
+		"posts/a/image.jpg":   strings.Repeat("abc", 10000),
+		"posts/b/data/d.json": "{}",
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	newFs := func(root string, names []string) afero.Fs {
+		fs := afero.NewMemMapFs()
+		for _, name := range names {
+			c.Assert(afero.WriteFile(fs, filepath.Join(root, name), []byte(files[name]), 0o644), qt.IsNil)
+		}
+		return fs
+	}
+	reversed := make([]string, len(names))
+	for i, name := range names {
+		reversed[len(names)-1-i] = name
+	}
+
+	fs := newFs("public", names)
+	hash, err := helpers.HashFs(fs, "public")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash, qt.HasLen, 32)
+
+	// Same files created in a different order and below another root.
+	hash2, err := helpers.HashFs(newFs("other", reversed), "other")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash2, qt.Equals, hash)
+
+	// Change the content of a file.
+	c.Assert(afero.WriteFile(fs, filepath.Join("public", "posts", "b", "index.html"), []byte("<html>B</html>"), 0o644), qt.IsNil)
+	hash3, err := helpers.HashFs(fs, "public")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash3, qt.Not(qt.Equals), hash)
+
+	// Rename a file.
+	c.Assert(fs.Rename(filepath.Join("public", "posts", "index.xml"), filepath.Join("public", "posts", "feed.xml")), qt.IsNil)
+	hash4, err := helpers.HashFs(fs, "public")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash4, qt.Not(qt.Equals), hash3)
+
+	// Errors are not skipped.
+	_, err = helpers.HashFs(failingOpenFs{Fs: fs, filename: filepath.Join("public", "css", "styles.css")}, "public")
+	c.Assert(err, qt.ErrorMatches, `failed to hash "css/styles.css": permission denied`)
+	c.Assert(errors.Is(err, os.ErrPermission), qt.IsTrue)
+
+	_, err = helpers.HashFs(fs, "doesnotexist")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestStableFileList(t *testing.T) {
+	c := qt.New(t)
+
+	expect := []string{
+		"10.txt",
+		"9.txt",
+		"B.txt",
+		"_x.txt",
+		"a-b.txt",
+		"a.txt",
+		"a/b.txt",
+		"a/c/d.txt",
+		"\u00e4.txt",
+	}
+
+	for _, order := range [][]int{{0, 1, 2, 3, 4, 5, 6, 7, 8}, {8, 7, 6, 5, 4, 3, 2, 1, 0}, {6, 2, 8, 0, 4, 7, 1, 5, 3}} {
+		fs := afero.NewMemMapFs()
+		c.Assert(fs.MkdirAll(filepath.Join("public", "empty"), 0o755), qt.IsNil)
+		for _, i := range order {
+			c.Assert(afero.WriteFile(fs, filepath.Join("public", filepath.FromSlash(expect[i])), []byte("x"), 0o644), qt.IsNil)
+		}
+		filenames, err := helpers.StableFileList(fs, "public")
+		c.Assert(err, qt.IsNil)
+		c.Assert(filenames, qt.DeepEquals, expect)
+	}
+
+	_, err := helpers.StableFileList(afero.NewMemMapFs(), "doesnotexist")
+	c.Assert(err, qt.ErrorMatches, `failed to walk "doesnotexist".*`)
+}
+
+func TestVerifyManifest(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"index.html":         "<html>home</html>",
+		"posts/a/index.html": "<html>a</html>",
+		"css/styles.css":     "body { color: red; }",
+	}
+	manifest := make(map[string]string)
+	for name, content := range files {
+		c.Assert(afero.WriteFile(fs, filepath.Join("public", filepath.FromSlash(name)), []byte(content), 0o644), qt.IsNil)
+		manifest[name] = helpers.MD5String(content)
+	}
+
+	verify := func() ([]string, []string, []string) {
+		missing, extra, changed, err := helpers.VerifyManifest(fs, "public", manifest)
+		c.Assert(err, qt.IsNil)
+		return missing, extra, changed
+	}
+
+	missing, extra, changed := verify()
+	c.Assert(missing, qt.IsNil)
+	c.Assert(extra, qt.IsNil)
+	c.Assert(changed, qt.IsNil)
+
+	c.Assert(fs.Remove(filepath.Join("public", "css", "styles.css")), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, filepath.Join("public", "posts", "old", "index.html"), []byte("stale"), 0o644), qt.IsNil)
+	c.Assert(afero.WriteFile(fs, filepath.Join("public", "index.html"), []byte("<html>Home</html>"), 0o644), qt.IsNil)
+
+	missing, extra, changed = verify()
+	c.Assert(missing, qt.DeepEquals, []string{"css/styles.css"})
+	c.Assert(extra, qt.DeepEquals, []string{"posts/old/index.html"})
+	c.Assert(changed, qt.DeepEquals, []string{"index.html"})
+
+	_, _, _, err := helpers.VerifyManifest(failingOpenFs{Fs: fs, filename: filepath.Join("public", "index.html")}, "public", manifest)
+	c.Assert(err, qt.ErrorMatches, `failed to hash "index.html": permission denied`)
+}
+
+func TestPrintFsDetailed(t *testing.T) {
+	c := qt.New(t)
+
+	mfs := afero.NewMemMapFs()
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, f := range []struct {
+		name    string
+		content string
+		hour    int
+	}{
+		{"root/c.txt", "c", 3},
+		{"root/a.txt", "aaa", 1},
+		{"root/b/d.txt", "dd", 0},
+		{"root/b/e.txt", "eeeee", 2},
+	} {
+		c.Assert(afero.WriteFile(mfs, f.name, []byte(f.content), 0644), qt.IsNil)
+		modTime := t1.Add(time.Duration(f.hour) * time.Hour)
+		c.Assert(mfs.Chtimes(f.name, modTime, modTime), qt.IsNil)
+	}
+	for i, dir := range []string{"root", "root/b"} {
+		modTime := t1.Add(time.Duration(10+i) * time.Hour)
+		c.Assert(mfs.Chtimes(dir, modTime, modTime), qt.IsNil)
+	}
+	fs := statErrorFs{Fs: mfs, failPath: filepath.FromSlash("root/b/d.txt")}
+
+	print := func(opts helpers.PrintFsOptions) string {
+		var buf bytes.Buffer
+		helpers.PrintFsDetailed(fs, "root", &buf, opts)
+		return filepath.ToSlash(buf.String())
+	}
+
+	// Walk order.
+	c.Assert(print(helpers.PrintFsOptions{}), qt.Equals, `root
+root/a.txt
+root/b
+root/b/d.txt  error: stat failed
+root/b/e.txt
+root/c.txt
+`)
+
+	c.Assert(print(helpers.PrintFsOptions{Size: true, ModTime: true, SortBy: "size"}), qt.Matches, `root/c.txt    1   2023-01-01T03:00:00Z
+root/a.txt    3   2023-01-01T01:00:00Z
+root/b/e.txt  5   2023-01-01T02:00:00Z
+root          \d+  \S+
+root/b        \d+  \S+
+root/b/d.txt  error: stat failed
+`)
+
+	c.Assert(print(helpers.PrintFsOptions{Mode: true, ModTime: true, SortBy: "modtime"}), qt.Matches, `root/a.txt    -rw-r--r--  2023-01-01T01:00:00Z
+root/b/e.txt  -rw-r--r--  2023-01-01T02:00:00Z
+root/c.txt    -rw-r--r--  2023-01-01T03:00:00Z
+root          d\S+  \S+
+root/b        d\S+  \S+
+root/b/d.txt  error: stat failed
+`)
+
+	c.Assert(print(helpers.PrintFsOptions{SortBy: "name"}), qt.Equals, `root
+root/a.txt
+root/b
+root/b/d.txt  error: stat failed
+root/b/e.txt
+root/c.txt
+`)
+
+	c.Assert(print(helpers.PrintFsOptions{Size: true}), qt.Matches, `root  \d+
+root/a.txt  3
+root/b  \d+
+root/b/d.txt  error: stat failed
+root/b/e.txt  5
+root/c.txt  1
+`)
+
+	// Missing root.
+	var buf bytes.Buffer
+	helpers.PrintFsDetailed(fs, "missing", &buf, helpers.PrintFsOptions{})
+	c.Assert(buf.String(), qt.Matches, "missing  error: .*\n")
+
+	helpers.PrintFs(nil, "root", &buf)
+	helpers.PrintFsDetailed(nil, "root", &buf, helpers.PrintFsOptions{})
+}
+
+func TestDeprecatedWithContext(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	logger := loggers.NewBasicLoggerForWriter(jww.LevelWarn, &buf)
+	oldWarnLog := helpers.DistinctWarnLog
+	helpers.DistinctWarnLog = helpers.NewDistinctLogger(logger)
+	defer func() {
+		helpers.DistinctWarnLog = oldWarnLog
+	}()
+
+	helpers.DeprecatedWithContext(".Foo", "Use .Bar.", false, `"index.html:12:3"`)
+	helpers.DeprecatedWithContext(".Foo", "Use .Bar.", false, `"index.html:12:3"`)
+	helpers.DeprecatedWithContext(".Foo", "Use .Bar.", false, `"single.html:4:5"`)
+	helpers.Deprecated(".Foo", "Use .Bar.", false)
+	helpers.Deprecated(".Foo", "Use .Bar.", false)
+
+	c.Assert(int(logger.LogCounters().WarnCounter.Count()), qt.Equals, 3)
+	out := buf.String()
+	c.Assert(out, qt.Contains, `"index.html:12:3": .Foo is deprecated and will be removed in a future release. Use .Bar.`)
+	c.Assert(out, qt.Contains, `"single.html:4:5": .Foo is deprecated`)
+	c.Assert(out, qt.Matches, `(?s).*WARN \S+ \S+ \.Foo is deprecated.*`)
+}
+
+type errorReader struct{}
+
+func (errorReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestCountLines(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect int
+	}{
+		{"", 0},
+		{"\n", 1},
+		{"a", 1},
+		{"a\nb", 2},
+		{"a\nb\n", 2},
+		{"a\r\nb\r\n", 2},
+		{"a\r\nb", 2},
+		{"a\n\n\nb", 4},
+		{strings.Repeat("abc\n", 20000), 20000},
+		{strings.Repeat("abc\n", 20000) + "abc", 20001},
+	} {
+		n, err := helpers.CountLines(strings.NewReader(test.in))
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+
+	_, err := helpers.CountLines(errorReader{})
+	c.Assert(err, qt.ErrorMatches, "read failed")
+}
+
+func TestCountWords(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect int
+	}{
+		{"", 0},
+		{"  \n ", 0},
+		{"Hugo", 1},
+		{"Hugo Rocks!", 2},
+		{"  Hugo \t Rocks!\r\nAgain\n", 3},
+		{"Hugo\u00a0Rocks\u3000Again", 3},
+		{"Blåbærsyltetøy er godt", 3},
+		// No spaces between words, so this under-counts.
+		{"日本語の文章です。", 1},
+		{"日本語 の 文章 です。", 4},
+		{"\xff", 1},
+		{"a \xff", 2},
+	} {
+		n, err := helpers.CountWords(strings.NewReader(test.in))
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+
+	// Multi-byte runes split across the read chunks.
+	for i := 0; i < 4; i++ {
+		in := strings.Repeat("a", 32*1024-i) + "ø\u3000å\u00a0日 b"
+		n, err := helpers.CountWords(strings.NewReader(in))
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 4, qt.Commentf("%d", i))
+	}
+
+	_, err := helpers.CountWords(errorReader{})
+	c.Assert(err, qt.ErrorMatches, "read failed")
+}
+
+func TestIsWhitespaceUnicode(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		r       rune
+		ascii   bool
+		unicode bool
+	}{
+		{' ', true, true},
+		{'\t', true, true},
+		{'\n', true, true},
+		{'\r', true, true},
+		{'\v', false, true},
+		{'\f', false, true},
+		{'\u0085', false, true}, // Next line
+		{'\u00a0', false, true}, // No-break space
+		{'\u1680', false, true}, // Ogham space mark
+		{'\u180e', false, true}, // Mongolian vowel separator
+		{'\u2000', false, true}, // En quad
+		{'\u2009', false, true}, // Thin space
+		{'\u200a', false, true}, // Hair space
+		{'\u200b', false, true}, // Zero width space
+		{'\u2028', false, true}, // Line separator
+		{'\u2029', false, true}, // Paragraph separator
+		{'\u202f', false, true}, // Narrow no-break space
+		{'\u205f', false, true}, // Medium mathematical space
+		{'\u2060', false, true}, // Word joiner
+		{'\u3000', false, true}, // Ideographic space
+		{'\ufeff', false, true}, // Zero width no-break space
+		{'a', false, false},
+		{'-', false, false},
+		{'\u200c', false, false}, // Zero width non-joiner
+		{'\u200d', false, false}, // Zero width joiner
+		{'日', false, false},
+	} {
+		c.Assert(helpers.IsWhitespace(test.r), qt.Equals, test.ascii, qt.Commentf("%U", test.r))
+		c.Assert(helpers.IsWhitespaceUnicode(test.r), qt.Equals, test.unicode, qt.Commentf("%U", test.r))
+	}
+}
+
+func TestMD5FromReader(t *testing.T) {
+	c := qt.New(t)
+
+	h, err := helpers.MD5FromReader(strings.NewReader("Hugo Rocks!"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(h, qt.Equals, helpers.MD5String("Hugo Rocks!"))
+
+	h, err = helpers.MD5FromReader(strings.NewReader(""))
+	c.Assert(err, qt.IsNil)
+	c.Assert(h, qt.Equals, "d41d8cd98f00b204e9800998ecf8427e")
+
+	// Fails partway through.
+	r := io.MultiReader(strings.NewReader("Hugo Rocks!"), errorReader{})
+	h, err = helpers.MD5FromReader(r)
+	c.Assert(err, qt.ErrorMatches, "read failed")
+	c.Assert(h, qt.Equals, "")
+}
+
+func TestHashAndSize(t *testing.T) {
+	c := qt.New(t)
+
+	for _, in := range []string{"", "Hugo Rocks!", strings.Repeat("abcdefghij", 10000)} {
+		digest, size, err := helpers.HashAndSize(strings.NewReader(in), md5.New())
+		c.Assert(err, qt.IsNil)
+		c.Assert(size, qt.Equals, int64(len(in)))
+		c.Assert(digest, qt.Equals, helpers.MD5String(in))
+
+		digest, size, err = helpers.HashAndSize(strings.NewReader(in), sha256.New())
+		c.Assert(err, qt.IsNil)
+		c.Assert(size, qt.Equals, int64(len(in)))
+		c.Assert(digest, qt.Equals, fmt.Sprintf("%x", sha256.Sum256([]byte(in))))
+	}
+
+	digest, size, err := helpers.HashAndSize(io.MultiReader(strings.NewReader("Hugo Rocks!"), errorReader{}), md5.New())
+	c.Assert(err, qt.ErrorMatches, "read failed")
+	c.Assert(digest, qt.Equals, "")
+	c.Assert(size, qt.Equals, int64(11))
+}
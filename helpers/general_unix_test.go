@@ -0,0 +1,77 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package helpers_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestListenerFromFD(t *testing.T) {
+	c := qt.New(t)
+
+	// ListenerFromFD takes ownership of the file descriptor, so pass it a
+	// duplicate of the one owned by f.
+	dupFd := func(f *os.File) uintptr {
+		defer f.Close()
+		fd, err := syscall.Dup(int(f.Fd()))
+		c.Assert(err, qt.IsNil)
+		return uintptr(fd)
+	}
+
+	tl, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	defer tl.Close()
+	f, err := tl.(*net.TCPListener).File()
+	c.Assert(err, qt.IsNil)
+
+	l, addr, err := helpers.ListenerFromFD(dupFd(f))
+	c.Assert(err, qt.IsNil)
+	defer l.Close()
+	c.Assert(addr.Port, qt.Equals, tl.Addr().(*net.TCPAddr).Port)
+
+	// The inherited listener accepts connections.
+	go func() {
+		conn, err := net.Dial("tcp", addr.String())
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := l.Accept()
+	c.Assert(err, qt.IsNil)
+	conn.Close()
+
+	// Not a TCP listener.
+	ul, err := net.Listen("unix", filepath.Join(t.TempDir(), "hugo.sock"))
+	c.Assert(err, qt.IsNil)
+	defer ul.Close()
+	f, err = ul.(*net.UnixListener).File()
+	c.Assert(err, qt.IsNil)
+	_, _, err = helpers.ListenerFromFD(dupFd(f))
+	c.Assert(err, qt.ErrorMatches, "unable to obtain a valid tcp port: .*")
+
+	// Not a socket.
+	f, err = os.Open(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	_, _, err = helpers.ListenerFromFD(dupFd(f))
+	c.Assert(err, qt.ErrorMatches, `file descriptor \d+ is not a listening socket: .*`)
+}
@@ -0,0 +1,225 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// FindMixedContent returns the src, srcset and href values in the given HTML
+// document that load a resource over plain http://, which browsers will block
+// or warn about when the page itself is served over HTTPS.
+// Navigational links (<a> and <area>) are not considered resources.
+// Protocol-relative URLs (//example.org) are not reported, as they inherit the
+// page's scheme.
+// If pageIsHTTPS is false, nil is returned.
+func FindMixedContent(b []byte, pageIsHTTPS bool) []string {
+	if !pageIsHTTPS {
+		return nil
+	}
+
+	var found []string
+	z := html.NewTokenizer(bytes.NewReader(b))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return found
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if tok.Data == "a" || tok.Data == "area" {
+			continue
+		}
+		for _, attr := range tok.Attr {
+			switch attr.Key {
+			case "src", "href":
+				if isInsecureURL(attr.Val) {
+					found = append(found, strings.TrimSpace(attr.Val))
+				}
+			case "srcset":
+				for _, u := range parseSrcset(attr.Val) {
+					if isInsecureURL(u) {
+						found = append(found, u)
+					}
+				}
+			}
+		}
+	}
+}
+
+func isInsecureURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) >= len("http://") && strings.EqualFold(s[:len("http://")], "http://")
+}
+
+// parseSrcset returns the URLs in a srcset attribute value,
+// e.g. "image-1x.png 1x, image-2x.png 2x".
+func parseSrcset(s string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(s, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+// htmlVoidElements are the elements that never have an end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlPreformattedElements are the elements whose content is written as is by PrettyHTML.
+var htmlPreformattedElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// PrettyHTML reformats the HTML in b with one tag or text node per line,
+// indented by two spaces per nesting level, which is useful for debugging and
+// snapshot tests. The content of <pre>, <textarea>, <script> and <style> is kept
+// as is.
+// Note that this changes the whitespace between inline elements, so the
+// result is not meant to be served.
+func PrettyHTML(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	depth := 0
+
+	writeLine := func(s []byte) {
+		buf.WriteString(strings.Repeat("  ", depth))
+		buf.Write(s)
+		buf.WriteByte('\n')
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(b))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		case html.TextToken:
+			if text := bytes.TrimSpace(z.Raw()); len(text) > 0 {
+				writeLine(text)
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			switch {
+			case htmlVoidElements[tag]:
+				writeLine(z.Raw())
+			case htmlPreformattedElements[tag]:
+				buf.WriteString(strings.Repeat("  ", depth))
+				buf.Write(z.Raw())
+				if err := copyHTMLUntilEndTag(z, tag, &buf); err != nil {
+					return nil, err
+				}
+				buf.WriteByte('\n')
+			default:
+				writeLine(z.Raw())
+				depth++
+			}
+		case html.EndTagToken:
+			if depth > 0 {
+				depth--
+			}
+			writeLine(z.Raw())
+		default:
+			// Self-closing tags, comments and doctypes.
+			writeLine(z.Raw())
+		}
+	}
+}
+
+// copyHTMLUntilEndTag copies the raw tokens from z to w up to and including
+// the end tag of the element with the given name.
+func copyHTMLUntilEndTag(z *html.Tokenizer, tag string, w io.Writer) error {
+	nesting := 1
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+		}
+		if _, err := w.Write(z.Raw()); err != nil {
+			return err
+		}
+		if tt == html.StartTagToken || tt == html.EndTagToken {
+			name, _ := z.TagName()
+			if string(name) != tag {
+				continue
+			}
+			if tt == html.StartTagToken {
+				nesting++
+			} else {
+				nesting--
+			}
+			if nesting == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// NormalizeClassAttr sorts the space separated values of every class
+// attribute in the HTML in b, so e.g. class="b  a" and class="a b" both
+// become class="a b". Duplicate class names are removed.
+// Tags without a class attribute, and everything else, are written as is;
+// tags with a class attribute are written in the normalized form of the
+// HTML tokenizer, e.g. with double quoted attribute values.
+func NormalizeClassAttr(b []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(b))
+
+	z := html.NewTokenizer(bytes.NewReader(b))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return buf.Bytes()
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			buf.Write(z.Raw())
+			continue
+		}
+
+		raw := append([]byte(nil), z.Raw()...)
+		tok := z.Token()
+		var hasClass bool
+		for i, attr := range tok.Attr {
+			if attr.Namespace == "" && attr.Key == "class" {
+				hasClass = true
+				classes := UniqueStrings(strings.Fields(attr.Val))
+				sort.Strings(classes)
+				tok.Attr[i].Val = strings.Join(classes, " ")
+			}
+		}
+		if hasClass {
+			buf.WriteString(tok.String())
+		} else {
+			buf.Write(raw)
+		}
+	}
+}
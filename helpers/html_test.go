@@ -0,0 +1,103 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestFindMixedContent(t *testing.T) {
+	c := qt.New(t)
+
+	doc := []byte(`<html><head>
+<link rel="stylesheet" href="http://example.org/style.css">
+<script src="https://example.org/main.js"></script>
+</head>
+<body>
+<a href="http://example.org/">Link</a>
+<img src="HTTP://example.org/a.jpg" srcset="https://example.org/a-1x.jpg 1x, http://example.org/a-2x.jpg 2x">
+<img src="//example.org/b.jpg" />
+<img src="/c.jpg">
+</body></html>`)
+
+	c.Assert(helpers.FindMixedContent(doc, true), qt.DeepEquals, []string{
+		"http://example.org/style.css",
+		"HTTP://example.org/a.jpg",
+		"http://example.org/a-2x.jpg",
+	})
+	c.Assert(helpers.FindMixedContent(doc, false), qt.IsNil)
+	c.Assert(helpers.FindMixedContent([]byte(`<img src="https://example.org/a.jpg"><img src="//example.org/b.jpg">`), true), qt.IsNil)
+}
+
+func TestPrettyHTML(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := helpers.PrettyHTML([]byte(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Hello</title></head><body><!-- main --><div class="a"><p>Hello <em>world</em>!</p><br><img src="a.png"/></div><pre>  line 1
+    <b>line 2</b>  </pre><textarea>  keep
+  this  </textarea></body></html>`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>
+      Hello
+    </title>
+  </head>
+  <body>
+    <!-- main -->
+    <div class="a">
+      <p>
+        Hello
+        <em>
+          world
+        </em>
+        !
+      </p>
+      <br>
+      <img src="a.png"/>
+    </div>
+    <pre>  line 1
+    <b>line 2</b>  </pre>
+    <textarea>  keep
+  this  </textarea>
+  </body>
+</html>
+`)
+
+	// Already pretty HTML is left unchanged.
+	b2, err := helpers.PrettyHTML(b)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b2), qt.Equals, string(b))
+
+	b, err = helpers.PrettyHTML([]byte(`<pre><pre>a</pre> b </pre><p>c</p>`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "<pre><pre>a</pre> b </pre>\n<p>\n  c\n</p>\n")
+}
+
+func TestNormalizeClassAttr(t *testing.T) {
+	c := qt.New(t)
+
+	a := helpers.NormalizeClassAttr([]byte(`<div class="card  active shadow" id="x"><img src=a.png class="b a a"/><p>Hello</p></div>`))
+	b := helpers.NormalizeClassAttr([]byte(`<div class="shadow card active" id="x"><img src=a.png class="a b"/><p>Hello</p></div>`))
+	c.Assert(string(a), qt.Equals, `<div class="active card shadow" id="x"><img src="a.png" class="a b"/><p>Hello</p></div>`)
+	c.Assert(string(b), qt.Equals, string(a))
+
+	// Tags without class attributes are untouched.
+	in := `<!DOCTYPE html><a href='/a?x=1&y=2' data-class="b a">Link</a><br/><script>if (a < b) {}</script>`
+	c.Assert(string(helpers.NormalizeClassAttr([]byte(in))), qt.Equals, in)
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+const (
+	identiconGridSize = 5
+	identiconMaxSize  = 4096
+)
+
+var identiconBackground = color.RGBA{0xf0, 0xf0, 0xf0, 0xff}
+
+// Identicon creates a GitHub style identicon for the given seed, e.g. an email
+// address or user name, and returns it as a PNG image of size x size pixels.
+// The icon is a horizontally symmetric 5x5 grid of cells, where both the pattern
+// and the color are derived from the MD5 hash of the seed, so the same
+// seed always gives the same image.
+func Identicon(seed string, size int) ([]byte, error) {
+	if size < identiconGridSize || size > identiconMaxSize {
+		return nil, fmt.Errorf("identicon size must be between %d and %d, got %d", identiconGridSize, identiconMaxSize, size)
+	}
+
+	sum := md5.Sum([]byte(seed))
+
+	// The first 15 nibbles decide the 3 leftmost columns, the rest is mirrored.
+	var grid [identiconGridSize][identiconGridSize]bool
+	for i := 0; i < 15; i++ {
+		nibble := sum[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		row, col := i%identiconGridSize, i/identiconGridSize
+		filled := nibble%2 == 0
+		grid[row][col] = filled
+		grid[row][identiconGridSize-1-col] = filled
+	}
+
+	hue := float64(uint16(sum[12])<<8|uint16(sum[13])) / math.MaxUint16 * 360
+	saturation := 0.45 + float64(sum[14])/255*0.2
+	lightness := 0.45 + float64(sum[15])/255*0.2
+	foreground := hslToRGB(hue, saturation, lightness)
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{identiconBackground, foreground})
+	for y := 0; y < size; y++ {
+		row := y * identiconGridSize / size
+		for x := 0; x < size; x++ {
+			if grid[row][x*identiconGridSize/size] {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hslToRGB converts the given hue (0-360), saturation (0-1) and lightness (0-1) to RGB.
+func hslToRGB(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	toUint8 := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+
+	return color.RGBA{toUint8(r), toUint8(g), toUint8(b), 0xff}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestIdenticon(t *testing.T) {
+	c := qt.New(t)
+
+	b1, err := helpers.Identicon("jane@example.org", 100)
+	c.Assert(err, qt.IsNil)
+	b2, err := helpers.Identicon("jane@example.org", 100)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bytes.Equal(b1, b2), qt.IsTrue)
+
+	b3, err := helpers.Identicon("john@example.org", 100)
+	c.Assert(err, qt.IsNil)
+	c.Assert(bytes.Equal(b1, b3), qt.IsFalse)
+
+	img, err := png.Decode(bytes.NewReader(b1))
+	c.Assert(err, qt.IsNil)
+	c.Assert(img.Bounds().Dx(), qt.Equals, 100)
+	c.Assert(img.Bounds().Dy(), qt.Equals, 100)
+
+	// Horizontally symmetric.
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 50; x++ {
+			c.Assert(img.At(x, y), qt.Equals, img.At(99-x, y))
+		}
+	}
+
+	_, err = helpers.Identicon("jane@example.org", 2)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
@@ -0,0 +1,299 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+
+	"github.com/disintegration/gift"
+	"github.com/muesli/smartcrop"
+)
+
+const ssimWindowSize = 8
+
+// SSIM returns the mean structural similarity index (SSIM) of the images a and b,
+// which must have the same dimensions, see https://en.wikipedia.org/wiki/Structural_similarity
+// The index is 1 for identical images and decreases with the perceived
+// difference, e.g. from compression artifacts.
+// It is computed on the luma channel over 8x8 windows with a stride of 4 pixels.
+func SSIM(a, b image.Image) (float64, error) {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Dx() != bb.Dx() || ab.Dy() != bb.Dy() {
+		return 0, fmt.Errorf("image dimensions differ: %dx%d and %dx%d", ab.Dx(), ab.Dy(), bb.Dx(), bb.Dy())
+	}
+	width, height := ab.Dx(), ab.Dy()
+	if width == 0 || height == 0 {
+		return 0, errors.New("images are empty")
+	}
+
+	la, lb := luma(a), luma(b)
+
+	windowWidth, windowHeight := ssimWindowSize, ssimWindowSize
+	if width < windowWidth {
+		windowWidth = width
+	}
+	if height < windowHeight {
+		windowHeight = height
+	}
+	const stride = ssimWindowSize / 2
+
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+
+	var sum float64
+	var windows int
+	for y := 0; y+windowHeight <= height; y += stride {
+		for x := 0; x+windowWidth <= width; x += stride {
+			var meanA, meanB float64
+			for wy := y; wy < y+windowHeight; wy++ {
+				for wx := x; wx < x+windowWidth; wx++ {
+					meanA += la[wy*width+wx]
+					meanB += lb[wy*width+wx]
+				}
+			}
+			n := float64(windowWidth * windowHeight)
+			meanA /= n
+			meanB /= n
+
+			var varA, varB, covar float64
+			for wy := y; wy < y+windowHeight; wy++ {
+				for wx := x; wx < x+windowWidth; wx++ {
+					da, db := la[wy*width+wx]-meanA, lb[wy*width+wx]-meanB
+					varA += da * da
+					varB += db * db
+					covar += da * db
+				}
+			}
+			varA /= n
+			varB /= n
+			covar /= n
+
+			sum += ((2*meanA*meanB + c1) * (2*covar + c2)) / ((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+
+	return sum / float64(windows), nil
+}
+
+// luma returns the luma (Rec. 601) of every pixel in img, row by row, in the range 0-255.
+func luma(img image.Image) []float64 {
+	bounds := img.Bounds()
+	l := make([]float64, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			l = append(l, (0.299*float64(r)+0.587*float64(g)+0.114*float64(b))/257)
+		}
+	}
+	return l
+}
+
+// EncodeJPEGToTargetSize encodes img as a JPEG with the highest quality (1-100)
+// that gives at most maxBytes bytes, found by a binary search, and returns
+// the JPEG and the quality used.
+// An error is returned if even quality 1 is larger than maxBytes.
+func EncodeJPEGToTargetSize(img image.Image, maxBytes int) ([]byte, int, error) {
+	encode := func(quality int) ([]byte, error) {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var best []byte
+	bestQuality := 0
+	lo, hi := 1, 100
+	for lo <= hi {
+		quality := (lo + hi) / 2
+		b, err := encode(quality)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(b) <= maxBytes {
+			best, bestQuality = b, quality
+			lo = quality + 1
+		} else {
+			hi = quality - 1
+		}
+	}
+
+	if best == nil {
+		return nil, 0, fmt.Errorf("image can't be encoded as a JPEG of at most %d bytes", maxBytes)
+	}
+
+	return best, bestQuality, nil
+}
+
+// smartCropResizer implements the resizer needed by smartcrop.
+type smartCropResizer struct{}
+
+func (smartCropResizer) Resize(img image.Image, width, height uint) image.Image {
+	bounds := img.Bounds()
+	if width == 0 {
+		width = uint(math.Ceil(float64(bounds.Dx()) * float64(height) / float64(bounds.Dy())))
+	}
+	if height == 0 {
+		height = uint(math.Ceil(float64(bounds.Dy()) * float64(width) / float64(bounds.Dx())))
+	}
+	g := gift.New(gift.Resize(int(width), int(height), gift.LinearResampling))
+	dst := image.NewRGBA(g.Bounds(bounds))
+	g.Draw(dst, img)
+	return dst
+}
+
+// SmartCrop returns the most interesting region of img with the aspect ratio
+// of width x height, based on edge detection, skin tone and saturation, see
+// https://github.com/muesli/smartcrop
+// The region is as large as the aspect ratio allows, so img would typically
+// be cropped to it and then resized to width x height.
+func SmartCrop(img image.Image, width, height int) (image.Rectangle, error) {
+	if width <= 0 || height <= 0 {
+		return image.Rectangle{}, fmt.Errorf("crop width and height must be positive, got %dx%d", width, height)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return image.Rectangle{}, errors.New("image is empty")
+	}
+	if bounds.Dx() == width && bounds.Dy() == height {
+		return bounds, nil
+	}
+
+	rect, err := smartcrop.NewAnalyzer(smartCropResizer{}).FindBestCrop(img, width, height)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+
+	return bounds.Intersect(rect), nil
+}
+
+// ApplyFilter applies the named filter to img and returns the result as a new image.
+// The filters and their params are:
+//
+// - "blur": Gaussian blur with the given "radius" (sigma), > 0 and <= 100. Required.
+// - "sharpen": unsharp mask with the given "radius" (sigma, default 1) and "amount" (default 1), both > 0 and <= 100.
+// - "grayscale": no params.
+// - "brightness": changes the brightness by "percentage", in the range [-100, 100]. Required.
+func ApplyFilter(img image.Image, filter string, params map[string]float64) (image.Image, error) {
+	type paramSpec struct {
+		name       string
+		min, max   float64
+		defaultVal float64
+		required   bool
+		// Whether the min value is excluded from the range.
+		minExclusive bool
+	}
+
+	var specs []paramSpec
+	switch filter {
+	case "blur":
+		specs = []paramSpec{{name: "radius", min: 0, max: 100, required: true, minExclusive: true}}
+	case "sharpen":
+		specs = []paramSpec{
+			{name: "radius", min: 0, max: 100, defaultVal: 1, minExclusive: true},
+			{name: "amount", min: 0, max: 100, defaultVal: 1, minExclusive: true},
+		}
+	case "grayscale":
+	case "brightness":
+		specs = []paramSpec{{name: "percentage", min: -100, max: 100, required: true}}
+	default:
+		return nil, fmt.Errorf("unsupported image filter %q, use one of blur, sharpen, grayscale or brightness", filter)
+	}
+
+	values := make(map[string]float64)
+	for _, spec := range specs {
+		v, found := params[spec.name]
+		if !found {
+			if spec.required {
+				return nil, fmt.Errorf("image filter %q: missing param %q", filter, spec.name)
+			}
+			v = spec.defaultVal
+		}
+		if v < spec.min || v == spec.min && spec.minExclusive || v > spec.max || math.IsNaN(v) {
+			open := "["
+			if spec.minExclusive {
+				open = "("
+			}
+			return nil, fmt.Errorf("image filter %q: param %q must be in the range %s%g, %g], got %g", filter, spec.name, open, spec.min, spec.max, v)
+		}
+		values[spec.name] = v
+	}
+	for name := range params {
+		if _, found := values[name]; !found {
+			return nil, fmt.Errorf("image filter %q: unknown param %q", filter, name)
+		}
+	}
+
+	var f gift.Filter
+	switch filter {
+	case "blur":
+		f = gift.GaussianBlur(float32(values["radius"]))
+	case "sharpen":
+		f = gift.UnsharpMask(float32(values["radius"]), float32(values["amount"]), 0)
+	case "grayscale":
+		f = gift.Grayscale()
+	case "brightness":
+		f = gift.Brightness(float32(values["percentage"]))
+	}
+
+	g := gift.New(f)
+	dst := image.NewNRGBA(g.Bounds(img.Bounds()))
+	g.Draw(dst, img)
+	return dst, nil
+}
+
+// Overlay returns a copy of base with overlay drawn on top of it at position
+// x, y relative to base's top left corner, e.g. for a watermark or logo.
+// opacity is in the range [0, 1], where 0 leaves base unchanged and 1 draws
+// overlay as is (respecting its own transparency).
+// The position is clamped so overlay stays within base where possible.
+func Overlay(base, overlay image.Image, x, y int, opacity float64) (image.Image, error) {
+	if opacity < 0 || opacity > 1 || math.IsNaN(opacity) {
+		return nil, fmt.Errorf("overlay opacity must be between 0 and 1, got %g", opacity)
+	}
+
+	bounds := base.Bounds()
+	size := overlay.Bounds().Size()
+	clamp := func(v, size, max int) int {
+		if v > max-size {
+			v = max - size
+		}
+		if v < 0 {
+			v = 0
+		}
+		return v
+	}
+	x = clamp(x, size.X, bounds.Dx())
+	y = clamp(y, size.Y, bounds.Dy())
+
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), base, bounds.Min, draw.Src)
+
+	r := image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x, y).Add(size)}
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Round(opacity * 255))})
+	draw.DrawMask(dst, r, overlay, overlay.Bounds().Min, mask, image.Point{}, draw.Over)
+
+	return dst, nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+// newTestImage creates a width x height image with a color gradient.
+func newTestImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x * 255 / width), uint8(y * 255 / height), 128, 255})
+		}
+	}
+	return img
+}
+
+func TestSSIM(t *testing.T) {
+	c := qt.New(t)
+
+	img := newTestImage(64, 48)
+
+	ssim, err := helpers.SSIM(img, img)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ssim, qt.Equals, 1.0)
+
+	degrade := func(quality int) image.Image {
+		var buf bytes.Buffer
+		c.Assert(jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}), qt.IsNil)
+		degraded, err := jpeg.Decode(&buf)
+		c.Assert(err, qt.IsNil)
+		return degraded
+	}
+
+	high, err := helpers.SSIM(img, degrade(95))
+	c.Assert(err, qt.IsNil)
+	low, err := helpers.SSIM(img, degrade(1))
+	c.Assert(err, qt.IsNil)
+	c.Assert(high < 1 && high > 0.9, qt.IsTrue, qt.Commentf("%f", high))
+	c.Assert(low < high, qt.IsTrue, qt.Commentf("%f < %f", low, high))
+
+	// Noise.
+	noisy := image.NewNRGBA(img.Bounds())
+	for i := range noisy.Pix {
+		noisy.Pix[i] = uint8(i * 7919 % 256)
+	}
+	ssim, err = helpers.SSIM(img, noisy)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ssim < 0.1, qt.IsTrue, qt.Commentf("%f", ssim))
+
+	// Small images.
+	ssim, err = helpers.SSIM(newTestImage(3, 2), newTestImage(3, 2))
+	c.Assert(err, qt.IsNil)
+	c.Assert(ssim, qt.Equals, 1.0)
+
+	_, err = helpers.SSIM(img, newTestImage(48, 64))
+	c.Assert(err, qt.ErrorMatches, "image dimensions differ: 64x48 and 48x64")
+}
+
+func TestEncodeJPEGToTargetSize(t *testing.T) {
+	c := qt.New(t)
+
+	img := newTestImage(200, 150)
+
+	var buf bytes.Buffer
+	c.Assert(jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}), qt.IsNil)
+	maxSize := buf.Len()
+
+	target := maxSize / 2
+	b, quality, err := helpers.EncodeJPEGToTargetSize(img, target)
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(b) <= target, qt.IsTrue)
+	c.Assert(quality > 1 && quality < 100, qt.IsTrue)
+
+	// The next quality up would be too large.
+	buf.Reset()
+	c.Assert(jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality + 1}), qt.IsNil)
+	c.Assert(buf.Len() > target, qt.IsTrue)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(b))
+	c.Assert(err, qt.IsNil)
+	c.Assert(decoded.Bounds(), qt.Equals, img.Bounds())
+
+	_, quality, err = helpers.EncodeJPEGToTargetSize(img, maxSize)
+	c.Assert(err, qt.IsNil)
+	c.Assert(quality, qt.Equals, 100)
+
+	_, _, err = helpers.EncodeJPEGToTargetSize(img, 100)
+	c.Assert(err, qt.ErrorMatches, "image can't be encoded as a JPEG of at most 100 bytes")
+}
+
+func TestSmartCrop(t *testing.T) {
+	c := qt.New(t)
+
+	// A flat gray image with a detailed checkerboard in the lower right.
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	detail := image.Rect(300, 100, 380, 180)
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			col := color.NRGBA{128, 128, 128, 255}
+			if (image.Point{x, y}).In(detail) {
+				if (x/4+y/4)%2 == 0 {
+					col = color.NRGBA{255, 40, 40, 255}
+				} else {
+					col = color.NRGBA{0, 0, 0, 255}
+				}
+			}
+			img.Set(x, y, col)
+		}
+	}
+
+	rect, err := helpers.SmartCrop(img, 100, 100)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rect.Dx(), qt.Equals, rect.Dy())
+	c.Assert(rect.In(img.Bounds()), qt.IsTrue)
+	c.Assert(detail.Intersect(rect).Empty(), qt.IsFalse)
+	// The crop is in the right half of the image.
+	c.Assert(rect.Min.X >= 150, qt.IsTrue, qt.Commentf("%v", rect))
+
+	rect, err = helpers.SmartCrop(img, 400, 200)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rect, qt.Equals, img.Bounds())
+
+	_, err = helpers.SmartCrop(img, 0, 100)
+	c.Assert(err, qt.ErrorMatches, "crop width and height must be positive, got 0x100")
+}
+
+func TestApplyFilter(t *testing.T) {
+	c := qt.New(t)
+
+	// Vertical stripes.
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			col := color.NRGBA{200, 60, 30, 255}
+			if x%4 < 2 {
+				col = color.NRGBA{20, 120, 220, 255}
+			}
+			img.SetNRGBA(x, y, col)
+		}
+	}
+
+	// contrast returns the difference in red between two neighboring stripes.
+	contrast := func(img image.Image) int {
+		a := color.NRGBAModel.Convert(img.At(10, 10)).(color.NRGBA)
+		b := color.NRGBAModel.Convert(img.At(9, 10)).(color.NRGBA)
+		d := int(a.R) - int(b.R)
+		if d < 0 {
+			d = -d
+		}
+		return d
+	}
+
+	c.Run("blur", func(c *qt.C) {
+		blurred, err := helpers.ApplyFilter(img, "blur", map[string]float64{"radius": 2})
+		c.Assert(err, qt.IsNil)
+		c.Assert(blurred.Bounds(), qt.Equals, img.Bounds())
+		c.Assert(contrast(blurred) < contrast(img)/2, qt.IsTrue)
+
+		_, err = helpers.ApplyFilter(img, "blur", nil)
+		c.Assert(err, qt.ErrorMatches, `image filter "blur": missing param "radius"`)
+		_, err = helpers.ApplyFilter(img, "blur", map[string]float64{"radius": 0})
+		c.Assert(err, qt.ErrorMatches, `image filter "blur": param "radius" must be in the range \(0, 100\], got 0`)
+	})
+
+	c.Run("sharpen", func(c *qt.C) {
+		// Blur it first to have something to sharpen.
+		blurred, err := helpers.ApplyFilter(img, "blur", map[string]float64{"radius": 1})
+		c.Assert(err, qt.IsNil)
+		sharpened, err := helpers.ApplyFilter(blurred, "sharpen", nil)
+		c.Assert(err, qt.IsNil)
+		c.Assert(contrast(sharpened) > contrast(blurred), qt.IsTrue)
+		_, err = helpers.ApplyFilter(img, "sharpen", map[string]float64{"amount": 2, "radius": 0.5})
+		c.Assert(err, qt.IsNil)
+	})
+
+	c.Run("grayscale", func(c *qt.C) {
+		gray, err := helpers.ApplyFilter(img, "grayscale", nil)
+		c.Assert(err, qt.IsNil)
+		for _, p := range []image.Point{{0, 0}, {2, 5}, {19, 19}} {
+			r, g, b, _ := gray.At(p.X, p.Y).RGBA()
+			c.Assert(r == g && g == b, qt.IsTrue)
+		}
+		_, err = helpers.ApplyFilter(img, "grayscale", map[string]float64{"amount": 1})
+		c.Assert(err, qt.ErrorMatches, `image filter "grayscale": unknown param "amount"`)
+	})
+
+	c.Run("brightness", func(c *qt.C) {
+		lighter, err := helpers.ApplyFilter(img, "brightness", map[string]float64{"percentage": 30})
+		c.Assert(err, qt.IsNil)
+		darker, err := helpers.ApplyFilter(img, "brightness", map[string]float64{"percentage": -30})
+		c.Assert(err, qt.IsNil)
+		r0, _, _, _ := img.At(0, 0).RGBA()
+		r1, _, _, _ := lighter.At(0, 0).RGBA()
+		r2, _, _, _ := darker.At(0, 0).RGBA()
+		c.Assert(r1 > r0 && r0 > r2, qt.IsTrue)
+		_, err = helpers.ApplyFilter(img, "brightness", map[string]float64{"percentage": 101})
+		c.Assert(err, qt.ErrorMatches, `.*must be in the range \[-100, 100\], got 101`)
+	})
+
+	_, err := helpers.ApplyFilter(img, "sepia", nil)
+	c.Assert(err, qt.ErrorMatches, `unsupported image filter "sepia".*`)
+}
+
+func TestOverlay(t *testing.T) {
+	c := qt.New(t)
+
+	base := image.NewRGBA(image.Rect(0, 0, 50, 40))
+	draw.Draw(base, base.Bounds(), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	logo := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+
+	rgba := func(img image.Image, x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+
+	result, err := helpers.Overlay(base, logo, 5, 5, 0.5)
+	c.Assert(err, qt.IsNil)
+	c.Assert(result.Bounds(), qt.Equals, base.Bounds())
+	c.Assert(rgba(result, 5, 5), qt.Equals, color.RGBA{128, 0, 127, 255})
+	c.Assert(rgba(result, 14, 14), qt.Equals, color.RGBA{128, 0, 127, 255})
+	c.Assert(rgba(result, 4, 5), qt.Equals, color.RGBA{0, 0, 255, 255})
+	c.Assert(rgba(result, 15, 15), qt.Equals, color.RGBA{0, 0, 255, 255})
+	// The base is not modified.
+	c.Assert(rgba(base, 5, 5), qt.Equals, color.RGBA{0, 0, 255, 255})
+
+	result, err = helpers.Overlay(base, logo, 0, 0, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rgba(result, 0, 0), qt.Equals, color.RGBA{255, 0, 0, 255})
+
+	// Clamped to the bottom right corner.
+	result, err = helpers.Overlay(base, logo, 100, 100, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rgba(result, 49, 39), qt.Equals, color.RGBA{255, 0, 0, 255})
+	c.Assert(rgba(result, 40, 30), qt.Equals, color.RGBA{255, 0, 0, 255})
+	c.Assert(rgba(result, 39, 29), qt.Equals, color.RGBA{0, 0, 255, 255})
+
+	result, err = helpers.Overlay(base, logo, -5, -5, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rgba(result, 0, 0), qt.Equals, color.RGBA{255, 0, 0, 255})
+
+	_, err = helpers.Overlay(base, logo, 0, 0, 1.5)
+	c.Assert(err, qt.ErrorMatches, "overlay opacity must be between 0 and 1, got 1.5")
+}
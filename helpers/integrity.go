@@ -0,0 +1,80 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// newIntegrityHash returns a new hash for the given Subresource Integrity algorithm.
+func newIntegrityHash(algo string) (hash.Hash, error) {
+	switch algo {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha384":
+		return sha512.New384(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported integrity hash algorithm: %q, use either sha256, sha384 or sha512", algo)
+	}
+}
+
+// VerifyIntegrity verifies data against the given Subresource Integrity string,
+// e.g. "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=".
+// See https://developer.mozilla.org/en-US/docs/Web/Security/Subresource_Integrity
+func VerifyIntegrity(data []byte, expected string) error {
+	expected = strings.TrimSpace(expected)
+	algo, _, found := strings.Cut(expected, "-")
+	if !found {
+		return fmt.Errorf("invalid integrity string %q: expected <algo>-<base64 digest>", expected)
+	}
+	h, err := newIntegrityHash(algo)
+	if err != nil {
+		return err
+	}
+	h.Write(data)
+	actual := algo + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("integrity mismatch: expected %q, got %q", expected, actual)
+	}
+	return nil
+}
+
+// CSPHashes returns a Content-Security-Policy hash source expression, e.g.
+// 'sha256-qznLcsROx4GACP2dm0UCKCzCG+HiZ1guq6ZZDob/Tng=', for each of the given
+// inline script or style blocks. The blocks must be the exact content between
+// the opening and closing tags, as that is what the browser hashes.
+// The supported algorithms are sha256, sha384 and sha512.
+// See https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Security-Policy/script-src
+func CSPHashes(inlineContents [][]byte, algo string) ([]string, error) {
+	algo = strings.ToLower(algo)
+	h, err := newIntegrityHash(algo)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, len(inlineContents))
+	for i, content := range inlineContents {
+		h.Reset()
+		h.Write(content)
+		sources[i] = "'" + algo + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil)) + "'"
+	}
+	return sources, nil
+}
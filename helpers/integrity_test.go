@@ -0,0 +1,58 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestVerifyIntegrity(t *testing.T) {
+	c := qt.New(t)
+	data := []byte("Hugo Rocks!")
+
+	c.Assert(helpers.VerifyIntegrity(data, "sha256-pa0caWEhSlXeU8HObmDSe2t2H1SFH6ZeMwZkYN+moNs="), qt.IsNil)
+	c.Assert(helpers.VerifyIntegrity(data, "sha384-qn5KkTNj0vQoYgddgB8HCf9DvrC1qXhdafoKoGQRU0pTi4yqBMQivkH42GKDmiRJ"), qt.IsNil)
+
+	err := helpers.VerifyIntegrity([]byte("Hugo Rocks?"), "sha256-pa0caWEhSlXeU8HObmDSe2t2H1SFH6ZeMwZkYN+moNs=")
+	c.Assert(err, qt.ErrorMatches, `integrity mismatch: expected "sha256-pa0c.*", got "sha256-.*"`)
+
+	c.Assert(helpers.VerifyIntegrity(data, "md5-abc"), qt.ErrorMatches, `unsupported integrity hash algorithm: "md5".*`)
+	c.Assert(helpers.VerifyIntegrity(data, "abc"), qt.ErrorMatches, `invalid integrity string.*`)
+}
+
+func TestCSPHashes(t *testing.T) {
+	c := qt.New(t)
+
+	// See https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Security-Policy/script-src#unsafe_inline_script
+	hashes, err := helpers.CSPHashes([][]byte{[]byte("alert('Hello, world.');"), []byte("Hugo Rocks!")}, "sha256")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hashes, qt.DeepEquals, []string{
+		"'sha256-qznLcsROx4GACP2dm0UCKCzCG+HiZ1guq6ZZDob/Tng='",
+		"'sha256-pa0caWEhSlXeU8HObmDSe2t2H1SFH6ZeMwZkYN+moNs='",
+	})
+	hashes, err = helpers.CSPHashes([][]byte{[]byte("Hugo Rocks!")}, "SHA384")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hashes, qt.DeepEquals, []string{
+		"'sha384-qn5KkTNj0vQoYgddgB8HCf9DvrC1qXhdafoKoGQRU0pTi4yqBMQivkH42GKDmiRJ'",
+	})
+	hashes, err = helpers.CSPHashes(nil, "sha256")
+	c.Assert(err, qt.IsNil)
+	c.Assert(hashes, qt.HasLen, 0)
+
+	_, err = helpers.CSPHashes([][]byte{[]byte("a")}, "md5")
+	c.Assert(err, qt.ErrorMatches, `unsupported integrity hash algorithm: "md5".*`)
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"io"
+)
+
+// DetectLineEnding returns the most common line ending in b, one of "\n",
+// "\r\n" or "\r" (classic Mac OS). Ties and text without any line endings
+// give "\n".
+func DetectLineEnding(b []byte) string {
+	var lf, crlf, cr int
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '\n':
+			lf++
+		case '\r':
+			if i+1 < len(b) && b[i+1] == '\n' {
+				crlf++
+				i++
+			} else {
+				cr++
+			}
+		}
+	}
+
+	switch {
+	case crlf > lf && crlf >= cr:
+		return "\r\n"
+	case cr > lf && cr > crlf:
+		return "\r"
+	default:
+		return "\n"
+	}
+}
+
+// TrimTrailingWhitespace removes trailing spaces and tabs from every line in b.
+// The line endings are left as is, so files with Windows ("\r\n") or mixed
+// line endings keep them. If the dominant line ending is "\r", see
+// DetectLineEnding, lines are split on "\r".
+func TrimTrailingWhitespace(b []byte) []byte {
+	sep := byte('\n')
+	if DetectLineEnding(b) == "\r" {
+		sep = '\r'
+	}
+
+	out := make([]byte, 0, len(b))
+	for len(b) > 0 {
+		var line, ending []byte
+		if i := bytes.IndexByte(b, sep); i != -1 {
+			line, ending, b = b[:i], b[i:i+1], b[i+1:]
+		} else {
+			line, b = b, nil
+		}
+		if sep == '\n' && len(line) > 0 && line[len(line)-1] == '\r' {
+			line, ending = line[:len(line)-1], []byte("\r\n")
+		}
+		out = append(out, bytes.TrimRight(line, " \t")...)
+		out = append(out, ending...)
+	}
+
+	return out
+}
+
+// EnsureFinalNewline returns b ending with exactly one line ending, adding
+// one if missing and collapsing multiple trailing line endings into one.
+// ending is one of "\n", "\r\n" or "\r"; any other value, e.g. "", means
+// the line ending detected by DetectLineEnding.
+// Empty content, including content with only line endings, is returned empty.
+func EnsureFinalNewline(b []byte, ending string) []byte {
+	switch ending {
+	case "\n", "\r\n", "\r":
+	default:
+		ending = DetectLineEnding(b)
+	}
+
+	trimmed := bytes.TrimRight(b, "\r\n")
+	if len(trimmed) == 0 {
+		return []byte{}
+	}
+
+	out := make([]byte, len(trimmed), len(trimmed)+len(ending))
+	copy(out, trimmed)
+	return append(out, ending...)
+}
+
+// utf8BOM is the UTF-8 encoded byte order mark.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// StripBOM returns b without any leading UTF-8 byte order mark.
+func StripBOM(b []byte) []byte {
+	return bytes.TrimPrefix(b, utf8BOM)
+}
+
+// NewBOMStrippingReader returns a reader that reads from r, but drops any
+// leading UTF-8 byte order mark. Only the first three bytes are inspected.
+func NewBOMStrippingReader(r io.Reader) io.Reader {
+	return &bomStrippingReader{r: r}
+}
+
+type bomStrippingReader struct {
+	r       io.Reader
+	started bool
+	pending []byte // Bytes read while looking for the BOM, not yet returned.
+	err     error  // Any error from reading the pending bytes.
+}
+
+func (r *bomStrippingReader) Read(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		buf := make([]byte, len(utf8BOM))
+		n, err := io.ReadFull(r.r, buf)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		r.pending, r.err = StripBOM(buf[:n]), err
+	}
+
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestDetectLineEnding(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.DetectLineEnding([]byte("a\nb\n")), qt.Equals, "\n")
+	c.Assert(helpers.DetectLineEnding([]byte("a\r\nb\r\nc\n")), qt.Equals, "\r\n")
+	c.Assert(helpers.DetectLineEnding([]byte("a\rb\r")), qt.Equals, "\r")
+	c.Assert(helpers.DetectLineEnding([]byte("a\r\nb\n")), qt.Equals, "\n")
+	c.Assert(helpers.DetectLineEnding([]byte("a")), qt.Equals, "\n")
+	c.Assert(helpers.DetectLineEnding(nil), qt.Equals, "\n")
+}
+
+func TestTrimTrailingWhitespace(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"a  \nb\t\n  c \t \n", "a\nb\n  c\n"},
+		{"a  \r\nb\t\r\n", "a\r\nb\r\n"},
+		{"a \r\nb \nc\t\r\n", "a\r\nb\nc\r\n"},
+		{"a \rb \r", "a\rb\r"},
+		{"a \nb  ", "a\nb"},
+		{"  \n\t\n", "\n\n"},
+		// Only spaces and tabs are trimmed.
+		{"a\u00a0 \n", "a\u00a0\n"},
+		{"", ""},
+	} {
+		c.Assert(string(helpers.TrimTrailingWhitespace([]byte(test.in))), qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+}
+
+func TestEnsureFinalNewline(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		ending string
+		expect string
+	}{
+		{"a\nb", "\n", "a\nb\n"},
+		{"a\nb\n", "\n", "a\nb\n"},
+		{"a\nb\n\n\n", "\n", "a\nb\n"},
+		{"a\r\nb\r\n\r\n", "\r\n", "a\r\nb\r\n"},
+		{"a\r\nb", "", "a\r\nb\r\n"},
+		{"a", "", "a\n"},
+		{"a\n\n", "\r\n", "a\r\n"},
+		{"", "\n", ""},
+		{"\n\n", "\n", ""},
+	} {
+		c.Assert(string(helpers.EnsureFinalNewline([]byte(test.in), test.ending)), qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+
+	// The input is not modified.
+	in := []byte("a\n\n")
+	helpers.EnsureFinalNewline(in[:1], "\r\n")
+	c.Assert(string(in), qt.Equals, "a\n\n")
+}
+
+func TestStripBOM(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"", ""},
+		{"\ufeff", ""},
+		{"\ufeff+++\ntitle = \"Hugo\"\n+++", "+++\ntitle = \"Hugo\"\n+++"},
+		{"\ufeff\ufeffa", "\ufeffa"},
+		{"a\ufeffb", "a\ufeffb"},
+		{"ab", "ab"},
+		{"\xef\xbb", "\xef\xbb"},
+		{"\xef\xbbx", "\xef\xbbx"},
+	} {
+		c.Assert(string(helpers.StripBOM([]byte(test.in))), qt.Equals, test.expect, qt.Commentf("%q", test.in))
+
+		b, err := io.ReadAll(helpers.NewBOMStrippingReader(strings.NewReader(test.in)))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, test.expect, qt.Commentf("%q", test.in))
+
+		// One byte at a time.
+		b, err = io.ReadAll(iotest.OneByteReader(helpers.NewBOMStrippingReader(iotest.OneByteReader(strings.NewReader(test.in)))))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+
+	// Errors are passed on.
+	_, err := io.ReadAll(helpers.NewBOMStrippingReader(iotest.ErrReader(errors.New("read failed"))))
+	c.Assert(err, qt.ErrorMatches, "read failed")
+	_, err = io.ReadAll(helpers.NewBOMStrippingReader(io.MultiReader(strings.NewReader("\ufeffabc"), iotest.ErrReader(errors.New("read failed")))))
+	c.Assert(err, qt.ErrorMatches, "read failed")
+}
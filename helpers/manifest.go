@@ -0,0 +1,134 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ManifestOptions configures the web app manifest created by WebAppManifest.
+type ManifestOptions struct {
+	// Required.
+	Name string
+
+	ShortName   string
+	Description string
+	StartURL    string
+
+	// One of "fullscreen", "standalone", "minimal-ui" or "browser".
+	Display string
+
+	// CSS colors, see ParseColor.
+	ThemeColor      string
+	BackgroundColor string
+
+	// At least one icon is required.
+	Icons []ManifestIcon
+}
+
+// ManifestIcon is an icon in a web app manifest.
+type ManifestIcon struct {
+	// Required.
+	Src string
+
+	// Required, e.g. "192x192" or "16x16 32x32" or "any".
+	Sizes string
+
+	// The media type, e.g. "image/png".
+	Type string
+
+	// E.g. "any" or "maskable".
+	Purpose string
+}
+
+var (
+	manifestDisplayModes = map[string]bool{
+		"fullscreen": true,
+		"standalone": true,
+		"minimal-ui": true,
+		"browser":    true,
+	}
+	manifestIconSizesRe = regexp.MustCompile(`^(any|[1-9]\d*x[1-9]\d*)( [1-9]\d*x[1-9]\d*)*$`)
+)
+
+type webAppManifest struct {
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	StartURL        string         `json:"start_url,omitempty"`
+	Display         string         `json:"display,omitempty"`
+	ThemeColor      string         `json:"theme_color,omitempty"`
+	BackgroundColor string         `json:"background_color,omitempty"`
+	Icons           []manifestIcon `json:"icons"`
+}
+
+type manifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// WebAppManifest returns a web app manifest (manifest.webmanifest), see
+// https://www.w3.org/TR/appmanifest/
+// Empty optional fields are omitted and the icons keep their given order, so
+// the output is stable.
+func WebAppManifest(opts ManifestOptions) ([]byte, error) {
+	if opts.Name == "" {
+		return nil, errors.New("web app manifest: name must be set")
+	}
+	if len(opts.Icons) == 0 {
+		return nil, errors.New("web app manifest: at least one icon must be set")
+	}
+	if opts.Display != "" && !manifestDisplayModes[opts.Display] {
+		return nil, fmt.Errorf("web app manifest: invalid display mode %q", opts.Display)
+	}
+	for _, c := range []struct{ name, value string }{
+		{"theme_color", opts.ThemeColor},
+		{"background_color", opts.BackgroundColor},
+	} {
+		if c.value == "" {
+			continue
+		}
+		if _, err := ParseColor(c.value); err != nil {
+			return nil, fmt.Errorf("web app manifest: %s: %w", c.name, err)
+		}
+	}
+
+	manifest := webAppManifest{
+		Name:            opts.Name,
+		ShortName:       opts.ShortName,
+		Description:     opts.Description,
+		StartURL:        opts.StartURL,
+		Display:         opts.Display,
+		ThemeColor:      opts.ThemeColor,
+		BackgroundColor: opts.BackgroundColor,
+		Icons:           make([]manifestIcon, len(opts.Icons)),
+	}
+
+	for i, icon := range opts.Icons {
+		if icon.Src == "" {
+			return nil, fmt.Errorf("web app manifest: icon %d: src must be set", i+1)
+		}
+		if !manifestIconSizesRe.MatchString(icon.Sizes) {
+			return nil, fmt.Errorf("web app manifest: icon %q: invalid sizes %q", icon.Src, icon.Sizes)
+		}
+		manifest.Icons[i] = manifestIcon(icon)
+	}
+
+	return json.MarshalIndent(manifest, "", "  ")
+}
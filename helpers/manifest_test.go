@@ -0,0 +1,93 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestWebAppManifest(t *testing.T) {
+	c := qt.New(t)
+
+	opts := helpers.ManifestOptions{
+		Name:            "Hugo Docs",
+		ShortName:       "Hugo",
+		StartURL:        "/",
+		Display:         "standalone",
+		ThemeColor:      "#0a1922",
+		BackgroundColor: "white",
+		Icons: []helpers.ManifestIcon{
+			{Src: "/android-chrome-192x192.png", Sizes: "192x192", Type: "image/png"},
+			{Src: "/android-chrome-512x512.png", Sizes: "512x512", Type: "image/png", Purpose: "maskable"},
+			{Src: "/favicon.ico", Sizes: "16x16 32x32"},
+		},
+	}
+
+	b, err := helpers.WebAppManifest(opts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{
+  "name": "Hugo Docs",
+  "short_name": "Hugo",
+  "start_url": "/",
+  "display": "standalone",
+  "theme_color": "#0a1922",
+  "background_color": "white",
+  "icons": [
+    {
+      "src": "/android-chrome-192x192.png",
+      "sizes": "192x192",
+      "type": "image/png"
+    },
+    {
+      "src": "/android-chrome-512x512.png",
+      "sizes": "512x512",
+      "type": "image/png",
+      "purpose": "maskable"
+    },
+    {
+      "src": "/favicon.ico",
+      "sizes": "16x16 32x32"
+    }
+  ]
+}`)
+
+	var m map[string]any
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+
+	b2, err := helpers.WebAppManifest(opts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(b2, qt.DeepEquals, b)
+
+	errorOpts := func(modify func(o *helpers.ManifestOptions)) error {
+		o := opts
+		o.Icons = append([]helpers.ManifestIcon(nil), opts.Icons...)
+		modify(&o)
+		_, err := helpers.WebAppManifest(o)
+		return err
+	}
+
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Name = "" }), qt.ErrorMatches, "web app manifest: name must be set")
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Icons = nil }), qt.ErrorMatches, "web app manifest: at least one icon must be set")
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Display = "window" }), qt.ErrorMatches, `web app manifest: invalid display mode "window"`)
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.ThemeColor = "#xyz" }), qt.ErrorMatches, `web app manifest: theme_color: invalid color: "#xyz"`)
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.BackgroundColor = "blurple" }), qt.ErrorMatches, `web app manifest: background_color: invalid color: "blurple"`)
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Icons[0].Src = "" }), qt.ErrorMatches, "web app manifest: icon 1: src must be set")
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Icons[1].Sizes = "512" }), qt.ErrorMatches, `web app manifest: icon "/android-chrome-512x512.png": invalid sizes "512"`)
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Icons[1].Sizes = "any" }), qt.IsNil)
+	c.Assert(errorOpts(func(o *helpers.ManifestOptions) { o.Display = ""; o.ThemeColor = "" }), qt.IsNil)
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/media"
+)
+
+var defaultMediaTypeRegistry = NewMediaTypeRegistry()
+
+// MediaTypeRegistry maps media types, e.g. "text/html", to their file suffixes
+// and back.
+// It is safe for concurrent use.
+type MediaTypeRegistry struct {
+	mu       sync.RWMutex
+	suffixes map[string][]string // media type => suffixes
+	types    map[string]string   // suffix => media type
+}
+
+// NewMediaTypeRegistry creates a new MediaTypeRegistry seeded with Hugo's
+// default media types.
+func NewMediaTypeRegistry() *MediaTypeRegistry {
+	r := &MediaTypeRegistry{
+		suffixes: make(map[string][]string),
+		types:    make(map[string]string),
+	}
+	for _, mt := range media.DefaultTypes {
+		r.Register(mt.Type, mt.Suffixes()...)
+	}
+	return r
+}
+
+// Register adds the given suffixes to mediaType.
+// Suffixes are case insensitive and any leading dot is ignored.
+// If a suffix is already registered to another media type, the first
+// registration wins for BySuffix, but the suffix is still listed in
+// SuffixesFor(mediaType).
+func (r *MediaTypeRegistry) Register(mediaType string, suffixes ...string) {
+	mediaType = strings.ToLower(mediaType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := r.suffixes[mediaType]; !found {
+		r.suffixes[mediaType] = nil
+	}
+
+	for _, suffix := range suffixes {
+		suffix = normalizeSuffix(suffix)
+		if suffix == "" || InStringArray(r.suffixes[mediaType], suffix) {
+			continue
+		}
+		r.suffixes[mediaType] = append(r.suffixes[mediaType], suffix)
+		if _, found := r.types[suffix]; !found {
+			r.types[suffix] = mediaType
+		}
+	}
+}
+
+// BySuffix returns the media type registered first for the given suffix.
+func (r *MediaTypeRegistry) BySuffix(suffix string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	mediaType, found := r.types[normalizeSuffix(suffix)]
+	return mediaType, found
+}
+
+// SuffixesFor returns the suffixes registered for mediaType, in registration order.
+func (r *MediaTypeRegistry) SuffixesFor(mediaType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	suffixes := r.suffixes[strings.ToLower(mediaType)]
+	if suffixes == nil {
+		return nil
+	}
+	return append([]string(nil), suffixes...)
+}
+
+func (r *MediaTypeRegistry) hasType(mediaType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, found := r.suffixes[strings.ToLower(mediaType)]
+	return found
+}
+
+func normalizeSuffix(suffix string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(suffix), "."))
+}
+
+// ValidateOutputFormat checks that suffix is an allowed file extension for
+// mediaType, e.g. "html" for "text/html". An empty suffix is valid, as the
+// output format will then use the media type's first suffix.
+// It returns an error describing the mismatch, suitable for logging as a warning.
+func ValidateOutputFormat(name, mediaType, suffix string) error {
+	if !defaultMediaTypeRegistry.hasType(mediaType) {
+		return fmt.Errorf("output format %q: unknown media type %q", name, mediaType)
+	}
+
+	suffix = normalizeSuffix(suffix)
+	if suffix == "" {
+		return nil
+	}
+
+	suffixes := defaultMediaTypeRegistry.SuffixesFor(mediaType)
+	if InStringArray(suffixes, suffix) {
+		return nil
+	}
+
+	return fmt.Errorf("output format %q: suffix %q does not match media type %q, expected one of %q", name, suffix, mediaType, suffixes)
+}
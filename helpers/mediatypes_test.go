@@ -0,0 +1,58 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestValidateOutputFormat(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.ValidateOutputFormat("html", "text/html", "html"), qt.IsNil)
+	c.Assert(helpers.ValidateOutputFormat("html", "text/html", ".HTML"), qt.IsNil)
+	c.Assert(helpers.ValidateOutputFormat("html", "text/html", ""), qt.IsNil)
+	c.Assert(helpers.ValidateOutputFormat("json", "application/json", "txt"), qt.ErrorMatches, `output format "json": suffix "txt" does not match media type "application/json".*`)
+	c.Assert(helpers.ValidateOutputFormat("foo", "application/foo", "foo"), qt.ErrorMatches, `output format "foo": unknown media type "application/foo"`)
+}
+
+func TestMediaTypeRegistry(t *testing.T) {
+	c := qt.New(t)
+	r := helpers.NewMediaTypeRegistry()
+
+	mt, found := r.BySuffix("html")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(mt, qt.Equals, "text/html")
+	mt, found = r.BySuffix(".JPG")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(mt, qt.Equals, "image/jpeg")
+	_, found = r.BySuffix("foo")
+	c.Assert(found, qt.IsFalse)
+
+	r.Register("application/x-foo", ".foo", "FOOBAR", "foo")
+	mt, found = r.BySuffix("foobar")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(mt, qt.Equals, "application/x-foo")
+	c.Assert(r.SuffixesFor("application/x-foo"), qt.DeepEquals, []string{"foo", "foobar"})
+	c.Assert(r.SuffixesFor("application/x-bar"), qt.IsNil)
+
+	// First registration wins.
+	r.Register("text/x-foo", "foo")
+	mt, _ = r.BySuffix("foo")
+	c.Assert(mt, qt.Equals, "application/x-foo")
+	c.Assert(r.SuffixesFor("text/x-foo"), qt.DeepEquals, []string{"foo"})
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"sort"
+
+	"github.com/gohugoio/hugo/compare"
+)
+
+// MenuEntry is a minimal, template-independent menu entry used by the menu
+// helpers in this package. See the navigation package for the full menu model.
+type MenuEntry struct {
+	Identifier string
+	Name       string
+	URL        string
+	Weight     int
+
+	// Active is set if this entry points to the current page.
+	Active bool
+	// HasActiveChild is set if any of the descendants of this entry is active.
+	HasActiveChild bool
+
+	Children []MenuEntry
+}
+
+// KeyName returns the key used to identify this entry, the Identifier if
+// set, else the Name.
+func (m MenuEntry) KeyName() string {
+	if m.Identifier != "" {
+		return m.Identifier
+	}
+	return m.Name
+}
+
+// MarkActiveTrail returns a copy of menu with Active set on the entries
+// pointing to activeURL and HasActiveChild set on all of their ancestors,
+// e.g. to expand the active branch of a nested menu.
+// URLs are compared in their canonical form, see CanonicalizeURL.
+// Entries without a URL never match.
+func MarkActiveTrail(menu []MenuEntry, activeURL string) []MenuEntry {
+	marked, _ := markActiveTrail(menu, CanonicalizeURL(activeURL))
+	return marked
+}
+
+func markActiveTrail(menu []MenuEntry, activeURL string) ([]MenuEntry, bool) {
+	if menu == nil {
+		return nil, false
+	}
+
+	var anyActive bool
+	marked := make([]MenuEntry, len(menu))
+	for i, entry := range menu {
+		var childActive bool
+		entry.Children, childActive = markActiveTrail(entry.Children, activeURL)
+		entry.HasActiveChild = childActive
+		entry.Active = entry.URL != "" && CanonicalizeURL(entry.URL) == activeURL
+		anyActive = anyActive || entry.Active || childActive
+		marked[i] = entry
+	}
+
+	return marked, anyActive
+}
+
+// MergeMenus combines the menu entries defined in config with those defined
+// in page front matter. Entries are deduplicated by KeyName, with page
+// entries replacing config entries with the same key.
+// The result is sorted the same way as menus in templates: by weight, with
+// unweighted (zero) entries last, then by name.
+func MergeMenus(configMenu, pageMenus []MenuEntry) []MenuEntry {
+	merged := make([]MenuEntry, 0, len(configMenu)+len(pageMenus))
+	positions := make(map[string]int)
+
+	for _, menu := range [][]MenuEntry{configMenu, pageMenus} {
+		for _, entry := range menu {
+			key := entry.KeyName()
+			if i, found := positions[key]; found {
+				merged[i] = entry
+				continue
+			}
+			positions[key] = len(merged)
+			merged = append(merged, entry)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		m1, m2 := merged[i], merged[j]
+		if m1.Weight == m2.Weight {
+			c := compare.Strings(m1.Name, m2.Name)
+			if c == 0 {
+				return m1.Identifier < m2.Identifier
+			}
+			return c < 0
+		}
+		if m2.Weight == 0 {
+			return true
+		}
+		if m1.Weight == 0 {
+			return false
+		}
+		return m1.Weight < m2.Weight
+	})
+
+	return merged
+}
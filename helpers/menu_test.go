@@ -0,0 +1,127 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func testMenu() []helpers.MenuEntry {
+	return []helpers.MenuEntry{
+		{Name: "Home", URL: "/"},
+		{
+			Name: "Docs", URL: "/docs/",
+			Children: []helpers.MenuEntry{
+				{Name: "Getting started", URL: "/docs/getting-started/"},
+				{
+					Name: "Content",
+					Children: []helpers.MenuEntry{
+						{Name: "Front matter", URL: "/docs/content/front-matter/"},
+						{Name: "Menus", URL: "/docs/content/menus/"},
+					},
+				},
+			},
+		},
+		{Name: "Blog", URL: "/blog/"},
+	}
+}
+
+func TestMarkActiveTrail(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("Deep", func(c *qt.C) {
+		menu := testMenu()
+		marked := helpers.MarkActiveTrail(menu, "/docs/content/menus/index.html")
+
+		docs := marked[1]
+		c.Assert(docs.Active, qt.IsFalse)
+		c.Assert(docs.HasActiveChild, qt.IsTrue)
+		content := docs.Children[1]
+		c.Assert(content.Active, qt.IsFalse)
+		c.Assert(content.HasActiveChild, qt.IsTrue)
+		c.Assert(content.Children[0].Active, qt.IsFalse)
+		c.Assert(content.Children[1].Active, qt.IsTrue)
+		c.Assert(content.Children[1].HasActiveChild, qt.IsFalse)
+		c.Assert(docs.Children[0].HasActiveChild, qt.IsFalse)
+
+		c.Assert(marked[0].Active || marked[0].HasActiveChild, qt.IsFalse)
+		c.Assert(marked[2].Active || marked[2].HasActiveChild, qt.IsFalse)
+
+		// The input is left untouched.
+		c.Assert(menu, qt.DeepEquals, testMenu())
+	})
+
+	c.Run("Top level", func(c *qt.C) {
+		marked := helpers.MarkActiveTrail(testMenu(), "/blog")
+		c.Assert(marked[2].Active, qt.IsTrue)
+		c.Assert(marked[2].HasActiveChild, qt.IsFalse)
+		c.Assert(marked[1].Active || marked[1].HasActiveChild, qt.IsFalse)
+
+		marked = helpers.MarkActiveTrail(testMenu(), "/")
+		c.Assert(marked[0].Active, qt.IsTrue)
+		// Entries without a URL never match.
+		c.Assert(marked[1].Children[1].Active, qt.IsFalse)
+	})
+
+	c.Run("No match", func(c *qt.C) {
+		c.Assert(helpers.MarkActiveTrail(testMenu(), "/about/"), qt.DeepEquals, testMenu())
+	})
+}
+
+func TestMergeMenus(t *testing.T) {
+	c := qt.New(t)
+
+	names := func(menu []helpers.MenuEntry) []string {
+		var s []string
+		for _, e := range menu {
+			s = append(s, e.Name)
+		}
+		return s
+	}
+
+	c.Run("Override by identifier", func(c *qt.C) {
+		configMenu := []helpers.MenuEntry{
+			{Identifier: "about", Name: "About", URL: "/about/", Weight: 10},
+			{Identifier: "blog", Name: "Blog", URL: "/blog/", Weight: 20},
+		}
+		pageMenus := []helpers.MenuEntry{
+			{Identifier: "about", Name: "About us", URL: "/about-us/", Weight: 30},
+		}
+		merged := helpers.MergeMenus(configMenu, pageMenus)
+		c.Assert(merged, qt.DeepEquals, []helpers.MenuEntry{
+			{Identifier: "blog", Name: "Blog", URL: "/blog/", Weight: 20},
+			{Identifier: "about", Name: "About us", URL: "/about-us/", Weight: 30},
+		})
+	})
+
+	c.Run("Distinct entries", func(c *qt.C) {
+		configMenu := []helpers.MenuEntry{{Name: "Blog", Weight: 2}, {Name: "Docs", Weight: 1}}
+		pageMenus := []helpers.MenuEntry{{Name: "About", Weight: 3}, {Identifier: "blog-post", Name: "Blog", Weight: 2}}
+		merged := helpers.MergeMenus(configMenu, pageMenus)
+		c.Assert(names(merged), qt.DeepEquals, []string{"Docs", "Blog", "Blog", "About"})
+		c.Assert(merged[1].Identifier, qt.Equals, "")
+		c.Assert(merged[2].Identifier, qt.Equals, "blog-post")
+	})
+
+	c.Run("Ordering", func(c *qt.C) {
+		configMenu := []helpers.MenuEntry{{Name: "Zero"}, {Name: "b", Weight: 5}, {Name: "A", Weight: 5}}
+		pageMenus := []helpers.MenuEntry{{Name: "Light", Weight: -1}, {Name: "Heavy", Weight: 100}}
+		c.Assert(names(helpers.MergeMenus(configMenu, pageMenus)), qt.DeepEquals, []string{"Light", "A", "b", "Heavy", "Zero"})
+	})
+
+	c.Assert(helpers.MergeMenus(nil, nil), qt.HasLen, 0)
+}
@@ -0,0 +1,324 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gohugoio/locales"
+	translators "github.com/gohugoio/localescompressed"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// FormatNumber formats n with the digit grouping and decimal separator of the
+// given language, e.g. "1,234.5" for "en" and "1.234,5" for "de", with the
+// given number of decimals. If decimals is negative, the shortest
+// representation of n is used.
+// If lang is not a valid BCP 47 language tag, n is formatted without grouping
+// and with a "." decimal separator.
+func FormatNumber(n float64, lang string, decimals int) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		if decimals < 0 {
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return strconv.FormatFloat(n, 'f', decimals, 64)
+	}
+
+	var opts []number.Option
+	if decimals >= 0 {
+		opts = append(opts, number.Scale(decimals))
+	}
+
+	return message.NewPrinter(tag).Sprint(number.Decimal(n, opts...))
+}
+
+// FormatCurrency formats amount in the currency with the given ISO 4217 code,
+// e.g. "USD", using the currency symbol, digit grouping, decimal separator and
+// symbol placement of the given language, e.g. "$1,234.50" for "en-US" and
+// "1.234,50 €" for "de-DE".
+// The number of decimals is the currency's standard, e.g. 2 for USD and 0 for JPY.
+func FormatCurrency(amount float64, currencyCode, lang string) (string, error) {
+	unit, err := currency.ParseISO(currencyCode)
+	if err != nil {
+		return "", fmt.Errorf("invalid currency code %q: %w", currencyCode, err)
+	}
+
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+	p := message.NewPrinter(tag)
+
+	c := translators.GetCurrency(unit.String())
+	if c == -1 {
+		// Valid, but not known to the translators, e.g. a newly added currency.
+		return p.Sprint(currency.Symbol(unit.Amount(amount))), nil
+	}
+
+	scale, _ := currency.Standard.Rounding(unit)
+	symbol := p.Sprint(currency.Symbol(unit))
+	var sign string
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	num := p.Sprint(number.Decimal(amount, number.Scale(scale)))
+
+	// The CLDR currency pattern decides where the symbol goes, e.g. "¤#,##0.00"
+	// or "#,##0.00 ¤", so format a zero amount to find out.
+	pattern := getTranslator(lang).FmtCurrency(0, 0, c)
+	if strings.HasPrefix(pattern, "0") {
+		rest := strings.TrimLeft(pattern, "0.,")
+		sep := rest[:len(rest)-len(strings.TrimLeftFunc(rest, unicode.IsSpace))]
+		return sign + num + sep + symbol, nil
+	}
+	head := pattern
+	if i := strings.IndexByte(pattern, '0'); i != -1 {
+		head = pattern[:i]
+	}
+	sep := head[len(strings.TrimRightFunc(head, unicode.IsSpace)):]
+	return sign + symbol + sep + num, nil
+}
+
+// getTranslator returns the translator for lang, falling back to its base
+// language, e.g. "de" for "de-CH", and then to English.
+func getTranslator(lang string) locales.Translator {
+	if tr := translators.GetTranslator(lang); tr != nil {
+		return tr
+	}
+	if base, _, found := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); found {
+		if tr := translators.GetTranslator(base); tr != nil {
+			return tr
+		}
+	}
+	return translators.GetTranslator("en")
+}
+
+// Pluralize returns singular if count is 1 or -1, else plural.
+// See PluralizeLocalized for languages with more than two plural forms.
+func Pluralize(count int, singular, plural string) string {
+	if count == 1 || count == -1 {
+		return singular
+	}
+	return plural
+}
+
+// PluralizeLocalized returns the form matching the CLDR cardinal plural
+// category of count in the given language, see
+// https://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+// The forms are keyed by category, one of "zero", "one", "two", "few", "many" and "other".
+// Russian, for example, uses "one" for 1 and 21, "few" for 2-4 and 22-24, and "many" for 5-20.
+// If the category is missing in forms, the "other" form is returned.
+func PluralizeLocalized(count int, lang string, forms map[string]string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+
+	n := count
+	if n < 0 {
+		n = -n
+	}
+
+	var category string
+	switch plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0) {
+	case plural.Zero:
+		category = "zero"
+	case plural.One:
+		category = "one"
+	case plural.Two:
+		category = "two"
+	case plural.Few:
+		category = "few"
+	case plural.Many:
+		category = "many"
+	default:
+		category = "other"
+	}
+
+	if form, found := forms[category]; found {
+		return form
+	}
+	return forms["other"]
+}
+
+// Ordinalize returns the ordinal form of n in the given language, e.g.
+// "1st", "2nd", "3rd", "11th" and "21st" in English.
+// Negative numbers keep their sign, e.g. "-1st", and zero is "0th".
+// English is currently the only supported language; for any other language
+// the number is returned as is.
+func Ordinalize(n int, lang string) string {
+	s := strconv.Itoa(n)
+
+	base, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(lang), "_", "-"), "-")
+	if base != "en" {
+		return s
+	}
+
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+
+	switch abs % 100 {
+	case 11, 12, 13:
+		return s + "th"
+	}
+
+	switch abs % 10 {
+	case 1:
+		return s + "st"
+	case 2:
+		return s + "nd"
+	case 3:
+		return s + "rd"
+	default:
+		return s + "th"
+	}
+}
+
+var (
+	englishOnes = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	englishTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishScales = []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion"}
+)
+
+// NumberToWords spells out n in the given language, e.g. "one hundred twenty-three"
+// for 123 and "minus forty-two" for -42 in English.
+// The American style without "and" is used, and the scale words follow the
+// short scale (million, billion, trillion etc.).
+// English is currently the only supported language.
+func NumberToWords(n int64, lang string) (string, error) {
+	base, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(lang), "_", "-"), "-")
+	if base != "en" {
+		return "", fmt.Errorf("spelling out numbers is not supported for language %q", lang)
+	}
+
+	if n == 0 {
+		return englishOnes[0], nil
+	}
+
+	var words []string
+	if n < 0 {
+		words = append(words, "minus")
+	}
+
+	// Use uint64 to handle math.MinInt64.
+	u := uint64(n)
+	if n < 0 {
+		u = -u
+	}
+
+	var groups []string
+	for scale := 0; u > 0; scale++ {
+		if group := u % 1000; group > 0 {
+			g := englishGroupToWords(int(group))
+			if englishScales[scale] != "" {
+				g += " " + englishScales[scale]
+			}
+			groups = append(groups, g)
+		}
+		u /= 1000
+	}
+
+	for i := len(groups) - 1; i >= 0; i-- {
+		words = append(words, groups[i])
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// englishGroupToWords spells out n in the range 1-999.
+func englishGroupToWords(n int) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n == 0:
+	case n < 20:
+		words = append(words, englishOnes[n])
+	case n%10 == 0:
+		words = append(words, englishTens[n/10])
+	default:
+		words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+	}
+	return strings.Join(words, " ")
+}
+
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"},
+	{1, "I"},
+}
+
+// ToRoman returns n as an upper case Roman numeral, e.g. "MMXXIII" for 2023.
+// n must be in the range 1-3999.
+func ToRoman(n int) (string, error) {
+	if n < 1 || n > 3999 {
+		return "", fmt.Errorf("%d is out of range for Roman numerals (1-3999)", n)
+	}
+
+	var b strings.Builder
+	for _, numeral := range romanNumerals {
+		for n >= numeral.value {
+			b.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+	return b.String(), nil
+}
+
+// FromRoman parses the Roman numeral s, e.g. "MMXXIII" or "mmxxiii".
+// Only numerals in their standard (shortest) form are accepted, so e.g.
+// "IIII" and "IC" are rejected.
+func FromRoman(s string) (int, error) {
+	upper := strings.ToUpper(s)
+
+	var n int
+	rest := upper
+	for _, numeral := range romanNumerals {
+		for strings.HasPrefix(rest, numeral.symbol) {
+			n += numeral.value
+			rest = rest[len(numeral.symbol):]
+		}
+	}
+
+	if rest != "" || n == 0 {
+		return 0, fmt.Errorf("invalid Roman numeral %q", s)
+	}
+
+	// Reject non-standard forms, e.g. "IIII" or "VV".
+	if canonical, err := ToRoman(n); err != nil || canonical != upper {
+		return 0, fmt.Errorf("invalid Roman numeral %q", s)
+	}
+
+	return n, nil
+}
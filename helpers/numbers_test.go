@@ -0,0 +1,216 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestFormatNumber(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.FormatNumber(1234.5, "en", 1), qt.Equals, "1,234.5")
+	c.Assert(helpers.FormatNumber(1234.5, "de", 1), qt.Equals, "1.234,5")
+	c.Assert(helpers.FormatNumber(1234567.891, "en", 2), qt.Equals, "1,234,567.89")
+	c.Assert(helpers.FormatNumber(1234.6, "en", 0), qt.Equals, "1,235")
+	c.Assert(helpers.FormatNumber(1234.5, "en", -1), qt.Equals, "1,234.5")
+	c.Assert(helpers.FormatNumber(-1234.5, "de-DE", 2), qt.Equals, "-1.234,50")
+	c.Assert(helpers.FormatNumber(1234.5, "not a language", 2), qt.Equals, "1234.50")
+	c.Assert(helpers.FormatNumber(1234.5, "not a language", -1), qt.Equals, "1234.5")
+}
+
+func TestFormatCurrency(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		amount   float64
+		currency string
+		lang     string
+		expect   string
+	}{
+		{1234.5, "USD", "en-US", "$1,234.50"},
+		{1234.5, "usd", "en_US", "$1,234.50"},
+		{1234.5, "EUR", "de-DE", "1.234,50 €"},
+		{1234.5, "EUR", "de-XX", "1.234,50 €"},
+		{1234.6, "JPY", "en", "¥1,235"},
+		{-1234.5, "USD", "en", "-$1,234.50"},
+		{1234.5, "EUR", "fr", "1\u00a0234,50 €"},
+		{1234.5, "USD", "", "$1,234.50"},
+	} {
+		s, err := helpers.FormatCurrency(test.amount, test.currency, test.lang)
+		c.Assert(err, qt.IsNil)
+		c.Assert(s, qt.Equals, test.expect, qt.Commentf("%s %s", test.currency, test.lang))
+	}
+
+	_, err := helpers.FormatCurrency(1234.5, "XYZ", "en")
+	c.Assert(err, qt.ErrorMatches, `invalid currency code "XYZ".*`)
+	_, err = helpers.FormatCurrency(1234.5, "DOLLARS", "en")
+	c.Assert(err, qt.ErrorMatches, `invalid currency code "DOLLARS".*`)
+}
+
+func TestPluralize(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.Pluralize(0, "comment", "comments"), qt.Equals, "comments")
+	c.Assert(helpers.Pluralize(1, "comment", "comments"), qt.Equals, "comment")
+	c.Assert(helpers.Pluralize(-1, "comment", "comments"), qt.Equals, "comment")
+	c.Assert(helpers.Pluralize(2, "comment", "comments"), qt.Equals, "comments")
+
+	en := map[string]string{"one": "comment", "other": "comments"}
+	c.Assert(helpers.PluralizeLocalized(1, "en", en), qt.Equals, "comment")
+	c.Assert(helpers.PluralizeLocalized(21, "en", en), qt.Equals, "comments")
+
+	ru := map[string]string{"one": "комментарий", "few": "комментария", "many": "комментариев", "other": "комментария"}
+	for _, test := range []struct {
+		count  int
+		expect string
+	}{
+		{1, "комментарий"},
+		{2, "комментария"},
+		{4, "комментария"},
+		{5, "комментариев"},
+		{11, "комментариев"},
+		{21, "комментарий"},
+		{22, "комментария"},
+		{25, "комментариев"},
+		{-3, "комментария"},
+	} {
+		c.Assert(helpers.PluralizeLocalized(test.count, "ru", ru), qt.Equals, test.expect, qt.Commentf("%d", test.count))
+	}
+
+	pl := map[string]string{"one": "plik", "few": "pliki", "other": "plików"}
+	c.Assert(helpers.PluralizeLocalized(1, "pl", pl), qt.Equals, "plik")
+	c.Assert(helpers.PluralizeLocalized(3, "pl", pl), qt.Equals, "pliki")
+	// No "many" form given, fall back to "other".
+	c.Assert(helpers.PluralizeLocalized(5, "pl", pl), qt.Equals, "plików")
+}
+
+func TestOrdinalize(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		n      int
+		expect string
+	}{
+		{0, "0th"},
+		{1, "1st"},
+		{2, "2nd"},
+		{3, "3rd"},
+		{4, "4th"},
+		{11, "11th"},
+		{12, "12th"},
+		{13, "13th"},
+		{21, "21st"},
+		{22, "22nd"},
+		{23, "23rd"},
+		{101, "101st"},
+		{111, "111th"},
+		{112, "112th"},
+		{1013, "1013th"},
+		{-1, "-1st"},
+		{-12, "-12th"},
+	} {
+		c.Assert(helpers.Ordinalize(test.n, "en"), qt.Equals, test.expect)
+	}
+
+	c.Assert(helpers.Ordinalize(22, "en-GB"), qt.Equals, "22nd")
+	c.Assert(helpers.Ordinalize(22, "de"), qt.Equals, "22")
+	c.Assert(helpers.Ordinalize(22, ""), qt.Equals, "22")
+}
+
+func TestNumberToWords(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		n      int64
+		expect string
+	}{
+		{0, "zero"},
+		{7, "seven"},
+		{13, "thirteen"},
+		{20, "twenty"},
+		{42, "forty-two"},
+		{100, "one hundred"},
+		{123, "one hundred twenty-three"},
+		{1000, "one thousand"},
+		{1001, "one thousand one"},
+		{2019, "two thousand nineteen"},
+		{1000000, "one million"},
+		{1234567, "one million two hundred thirty-four thousand five hundred sixty-seven"},
+		{-42, "minus forty-two"},
+		{math.MaxInt64, "nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred seven"},
+		{math.MinInt64, "minus nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred eight"},
+	} {
+		s, err := helpers.NumberToWords(test.n, "en")
+		c.Assert(err, qt.IsNil)
+		c.Assert(s, qt.Equals, test.expect)
+	}
+
+	s, err := helpers.NumberToWords(21, "en-US")
+	c.Assert(err, qt.IsNil)
+	c.Assert(s, qt.Equals, "twenty-one")
+
+	_, err = helpers.NumberToWords(21, "de")
+	c.Assert(err, qt.ErrorMatches, `spelling out numbers is not supported for language "de"`)
+}
+
+func TestRoman(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		n      int
+		expect string
+	}{
+		{1, "I"},
+		{4, "IV"},
+		{9, "IX"},
+		{14, "XIV"},
+		{40, "XL"},
+		{90, "XC"},
+		{400, "CD"},
+		{1994, "MCMXCIV"},
+		{2023, "MMXXIII"},
+		{3999, "MMMCMXCIX"},
+	} {
+		s, err := helpers.ToRoman(test.n)
+		c.Assert(err, qt.IsNil)
+		c.Assert(s, qt.Equals, test.expect)
+	}
+
+	for n := 1; n <= 3999; n++ {
+		s, err := helpers.ToRoman(n)
+		c.Assert(err, qt.IsNil)
+		back, err := helpers.FromRoman(s)
+		c.Assert(err, qt.IsNil)
+		c.Assert(back, qt.Equals, n)
+	}
+
+	n, err := helpers.FromRoman("mcmxciv")
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 1994)
+
+	for _, n := range []int{0, -1, 4000} {
+		_, err := helpers.ToRoman(n)
+		c.Assert(err, qt.ErrorMatches, ".*out of range.*")
+	}
+
+	for _, s := range []string{"", "IIII", "VV", "IC", "XM", "MMMM", "IIV", "ABC", "X I"} {
+		_, err := helpers.FromRoman(s)
+		c.Assert(err, qt.ErrorMatches, "invalid Roman numeral.*", qt.Commentf(s))
+	}
+}
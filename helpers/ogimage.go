@@ -0,0 +1,176 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/disintegration/gift"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	ogImageWidth  = 1200
+	ogImageHeight = 630
+
+	ogImageDefaultFontSize = 72
+	ogImageMinFontSize     = 16
+	ogImageDefaultPadding  = 80
+)
+
+// OGImageOptions configures RenderOGImage.
+type OGImageOptions struct {
+	// The title to draw. Required.
+	Title string
+
+	// The background color, default black. Ignored if BackgroundImage is set.
+	BackgroundColor color.Color
+	// An optional background image, scaled and cropped to fill the image.
+	BackgroundImage image.Image
+
+	// The text color, default white.
+	TextColor color.Color
+
+	// A TrueType or OpenType font, default Go Regular.
+	FontData []byte
+	// The maximum font size in points, default 72. The text is shrunk as needed to fit.
+	FontSize float64
+
+	// The space between the text and the image edges in pixels, default 80.
+	Padding int
+}
+
+// RenderOGImage draws the title in opts, wrapped at word boundaries, over a
+// background color or image and returns it as a 1200x630 PNG, the recommended
+// size for Open Graph images.
+// If the wrapped title doesn't fit, the font size is reduced until it does,
+// down to 16pt, and an error is returned if it still doesn't fit.
+func RenderOGImage(opts OGImageOptions) ([]byte, error) {
+	if strings.TrimSpace(opts.Title) == "" {
+		return nil, errors.New("OG image title must be set")
+	}
+	if opts.BackgroundColor == nil {
+		opts.BackgroundColor = color.Black
+	}
+	if opts.TextColor == nil {
+		opts.TextColor = color.White
+	}
+	if opts.FontData == nil {
+		opts.FontData = goregular.TTF
+	}
+	if opts.FontSize == 0 {
+		opts.FontSize = ogImageDefaultFontSize
+	}
+	if opts.Padding == 0 {
+		opts.Padding = ogImageDefaultPadding
+	}
+	if opts.Padding < 0 || 2*opts.Padding >= ogImageHeight {
+		return nil, fmt.Errorf("OG image padding must be between 0 and %d, got %d", ogImageHeight/2-1, opts.Padding)
+	}
+
+	f, err := opentype.Parse(opts.FontData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	maxWidth := ogImageWidth - 2*opts.Padding
+	maxHeight := ogImageHeight - 2*opts.Padding
+
+	var (
+		face  font.Face
+		lines []string
+	)
+	for size := opts.FontSize; ; size *= 0.9 {
+		if size < ogImageMinFontSize {
+			return nil, fmt.Errorf("OG image title %q does not fit", opts.Title)
+		}
+		face, err = opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		lines = wrapText(face, opts.Title, fixed.I(maxWidth))
+		if len(lines)*face.Metrics().Height.Ceil() <= maxHeight {
+			break
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, ogImageWidth, ogImageHeight))
+	if opts.BackgroundImage != nil {
+		g := gift.New(gift.ResizeToFill(ogImageWidth, ogImageHeight, gift.LinearResampling, gift.CenterAnchor))
+		g.Draw(img, opts.BackgroundImage)
+	} else {
+		draw.Draw(img, img.Bounds(), image.NewUniform(opts.BackgroundColor), image.Point{}, draw.Src)
+	}
+
+	// Center the text block vertically.
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	y := (ogImageHeight-len(lines)*lineHeight)/2 + metrics.Ascent.Ceil()
+
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(opts.TextColor), Face: face}
+	for _, line := range lines {
+		d.Dot = fixed.P(opts.Padding, y)
+		d.DrawString(line)
+		y += lineHeight
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// wrapText splits text into lines no wider than maxWidth when drawn with face,
+// breaking at whitespace, and within words that are too long to fit on a line.
+func wrapText(face font.Face, text string, maxWidth fixed.Int26_6) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= maxWidth {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		// Break words that don't fit on a line of their own.
+		line = ""
+		for _, r := range word {
+			if line != "" && font.MeasureString(face, line+string(r)) > maxWidth {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestRenderOGImage(t *testing.T) {
+	c := qt.New(t)
+
+	// textBounds returns the bounding box of the non-black pixels.
+	textBounds := func(b []byte) image.Rectangle {
+		img, err := png.Decode(bytes.NewReader(b))
+		c.Assert(err, qt.IsNil)
+		c.Assert(img.Bounds(), qt.Equals, image.Rect(0, 0, 1200, 630))
+		var r image.Rectangle
+		for y := 0; y < 630; y++ {
+			for x := 0; x < 1200; x++ {
+				if rr, _, _, _ := img.At(x, y).RGBA(); rr > 0 {
+					r = r.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		return r
+	}
+
+	short, err := helpers.RenderOGImage(helpers.OGImageOptions{Title: "Hello"})
+	c.Assert(err, qt.IsNil)
+	shortBounds := textBounds(short)
+	c.Assert(shortBounds.Empty(), qt.IsFalse)
+
+	long, err := helpers.RenderOGImage(helpers.OGImageOptions{Title: strings.Repeat("A very long title that must wrap ", 4)})
+	c.Assert(err, qt.IsNil)
+	longBounds := textBounds(long)
+	c.Assert(longBounds.In(image.Rect(80, 80, 1120, 550)), qt.IsTrue, qt.Commentf("%v", longBounds))
+	c.Assert(longBounds.Dy() > 2*shortBounds.Dy(), qt.IsTrue, qt.Commentf("%v %v", longBounds, shortBounds))
+
+	// A single word that's too long for a line is broken.
+	word, err := helpers.RenderOGImage(helpers.OGImageOptions{Title: strings.Repeat("W", 40), Padding: 50})
+	c.Assert(err, qt.IsNil)
+	c.Assert(textBounds(word).In(image.Rect(50, 50, 1150, 580)), qt.IsTrue)
+
+	// Background image.
+	bg := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for i := range bg.Pix {
+		bg.Pix[i] = 0xff
+	}
+	b, err := helpers.RenderOGImage(helpers.OGImageOptions{Title: "Hello", BackgroundImage: bg, TextColor: color.Black})
+	c.Assert(err, qt.IsNil)
+	img, err := png.Decode(bytes.NewReader(b))
+	c.Assert(err, qt.IsNil)
+	c.Assert(color.RGBAModel.Convert(img.At(0, 0)), qt.Equals, color.RGBA{255, 255, 255, 255})
+
+	_, err = helpers.RenderOGImage(helpers.OGImageOptions{Title: " "})
+	c.Assert(err, qt.ErrorMatches, "OG image title must be set")
+	_, err = helpers.RenderOGImage(helpers.OGImageOptions{Title: strings.Repeat("Way too long ", 1000)})
+	c.Assert(err, qt.ErrorMatches, ".*does not fit")
+	_, err = helpers.RenderOGImage(helpers.OGImageOptions{Title: "Hello", FontData: []byte("not a font")})
+	c.Assert(err, qt.ErrorMatches, "failed to parse font.*")
+}
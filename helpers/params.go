@@ -0,0 +1,83 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
+)
+
+// Params wraps a map of params, typically from front matter, with
+// case-insensitive lookups.
+type Params map[string]any
+
+// Get returns the value for key, which can be a dot separated path to a
+// nested value, e.g. "author.name". Keys are matched case-insensitively, but
+// an exact match is preferred. If there are several keys differing only in
+// case, and none matches exactly, the first in sort order is used.
+func (p Params) Get(key string) (any, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	var v any = map[string]any(p)
+	for _, k := range strings.Split(key, ".") {
+		var found bool
+		if v, found = lookupParamFold(v, k); !found {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// lookupParamFold looks up key in m, which must be a map to be found.
+func lookupParamFold(m any, key string) (any, bool) {
+	switch mm := m.(type) {
+	case maps.Params:
+		return lookupParamFold(map[string]any(mm), key)
+	case Params:
+		return lookupParamFold(map[string]any(mm), key)
+	case map[string]any:
+		if v, found := mm[key]; found {
+			return v, true
+		}
+		var match string
+		found := false
+		for k := range mm {
+			if strings.EqualFold(k, key) && (!found || k < match) {
+				match, found = k, true
+			}
+		}
+		if found {
+			return mm[match], true
+		}
+	case map[any]any:
+		if v, found := mm[key]; found {
+			return v, true
+		}
+		var match string
+		found := false
+		for k := range mm {
+			if ks, ok := k.(string); ok && strings.EqualFold(ks, key) && (!found || ks < match) {
+				match, found = ks, true
+			}
+		}
+		if found {
+			return mm[match], true
+		}
+	}
+	return nil, false
+}
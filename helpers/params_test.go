@@ -0,0 +1,77 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestParamsGet(t *testing.T) {
+	c := qt.New(t)
+
+	p := helpers.Params{
+		"Title": "Hugo",
+		"title": "hugo",
+		"Draft": false,
+		"Author": map[string]any{
+			"Name": "Bep",
+			"Social": maps.Params{
+				"github": "bep",
+			},
+		},
+		"legacy": map[any]any{
+			"Key": "value",
+			1:     "one",
+		},
+		"tags": []string{"a", "b"},
+	}
+
+	get := func(key string) any {
+		v, found := p.Get(key)
+		c.Assert(found, qt.IsTrue, qt.Commentf(key))
+		return v
+	}
+	notFound := func(key string) {
+		v, found := p.Get(key)
+		c.Assert(found, qt.IsFalse, qt.Commentf(key))
+		c.Assert(v, qt.IsNil)
+	}
+
+	// Exact matches are preferred.
+	c.Assert(get("Title"), qt.Equals, "Hugo")
+	c.Assert(get("title"), qt.Equals, "hugo")
+	c.Assert(get("TITLE"), qt.Equals, "Hugo")
+	c.Assert(get("DRAFT"), qt.Equals, false)
+	c.Assert(get("draft"), qt.Equals, false)
+	c.Assert(get("tags"), qt.DeepEquals, []string{"a", "b"})
+
+	c.Assert(get("author.name"), qt.Equals, "Bep")
+	c.Assert(get("AUTHOR.Name"), qt.Equals, "Bep")
+	c.Assert(get("author.social.GitHub"), qt.Equals, "bep")
+	c.Assert(get("legacy.key"), qt.Equals, "value")
+	c.Assert(get("author"), qt.DeepEquals, p["Author"])
+
+	notFound("")
+	notFound("missing")
+	notFound("author.email")
+	notFound("author.name.first")
+	notFound("title.x")
+	notFound("tags.0")
+	notFound("legacy.1")
+	notFound("author.")
+}
@@ -22,7 +22,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/gohugoio/hugo/common/herrors"
@@ -86,6 +89,8 @@ func ishex(c rune) bool {
 // a predefined set of special Unicode characters.
 // If RemovePathAccents configuration flag is enabled, Unicode accents
 // are also removed.
+// If TrimUnicodeWhitespace configuration flag is enabled, all Unicode
+// whitespace, see IsWhitespaceUnicode, is treated as spaces.
 // Hyphens in the original input are maintained.
 // Spaces will be replaced with a single hyphen, and sequential replacement hyphens will be reduced to one.
 func (p *PathSpec) UnicodeSanitize(s string) string {
@@ -93,6 +98,11 @@ func (p *PathSpec) UnicodeSanitize(s string) string {
 		s = text.RemoveAccentsString(s)
 	}
 
+	isSpace := unicode.IsSpace
+	if p.Cfg.TrimUnicodeWhitespace() {
+		isSpace = IsWhitespaceUnicode
+	}
+
 	source := []rune(s)
 	target := make([]rune, 0, len(source))
 	var (
@@ -118,7 +128,7 @@ func (p *PathSpec) UnicodeSanitize(s string) string {
 				prependHyphen = false
 			}
 			target = append(target, r)
-		} else if len(target) > 0 && !wasHyphen && unicode.IsSpace(r) {
+		} else if len(target) > 0 && !wasHyphen && isSpace(r) {
 			prependHyphen = true
 		}
 	}
@@ -126,6 +136,62 @@ func (p *PathSpec) UnicodeSanitize(s string) string {
 	return string(target)
 }
 
+// SlugDeduper makes slugs unique by appending -1, -2 etc. to slugs already seen,
+// so pages that slugify to the same path do not silently overwrite each other.
+// The zero value is ready to use, and it is safe for concurrent use.
+// Note that the suffixes depend on the order of the calls to Unique.
+type SlugDeduper struct {
+	mu   sync.Mutex
+	seen map[string]int // slug => the last suffix tried for that slug
+}
+
+// Unique returns slug if it has not been seen before, otherwise the first
+// slug-N (N >= 1) not seen before.
+func (d *SlugDeduper) Unique(slug string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen == nil {
+		d.seen = make(map[string]int)
+	}
+
+	n, found := d.seen[slug]
+	if !found {
+		d.seen[slug] = 0
+		return slug
+	}
+
+	for {
+		n++
+		candidate := slug + "-" + strconv.Itoa(n)
+		if _, taken := d.seen[candidate]; !taken {
+			d.seen[slug] = n
+			d.seen[candidate] = 0
+			return candidate
+		}
+	}
+}
+
+// DateFromFilename returns the date in a leading YYYY-MM-DD in the base name
+// of the given filename, e.g. 2023-05-16 for "posts/2023-05-16-hugo-rocks.md".
+// The date is in UTC. It returns false if there is no leading date or if it is
+// not a valid calendar date.
+func DateFromFilename(name string) (time.Time, bool) {
+	base := filepath.Base(filepath.FromSlash(name))
+	const layout = "2006-01-02"
+	if len(base) < len(layout) {
+		return time.Time{}, false
+	}
+	if len(base) > len(layout) && unicode.IsDigit(rune(base[len(layout)])) {
+		return time.Time{}, false
+	}
+	d, err := time.Parse(layout, base[:len(layout)])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
 func MakePathRelative(inPath string, possibleDirectories ...string) (string, error) {
 	for _, currentPath := range possibleDirectories {
 		if strings.HasPrefix(inPath, currentPath) {
@@ -447,6 +513,25 @@ func GetTempDir(subPath string, fs afero.Fs) string {
 	return afero.GetTempDir(fs, subPath)
 }
 
+// BuildTempDir creates a temporary directory below parent whose name is derived
+// from buildID, so concurrent builds with different IDs never share a directory,
+// while the same build ID always resolves to the same directory.
+// If parent is empty, os.TempDir is used.
+// The returned func removes the directory and everything below it.
+func BuildTempDir(parent, buildID string) (string, func() error, error) {
+	if buildID == "" {
+		return "", nil, errors.New("build ID must be set")
+	}
+	if parent == "" {
+		parent = os.TempDir()
+	}
+	dir := filepath.Join(parent, "hugo_build_"+MD5String(buildID)[:16])
+	if err := os.MkdirAll(dir, 0777); err != nil { // before umask
+		return "", nil, fmt.Errorf("failed to create build temp dir: %w", err)
+	}
+	return dir, func() error { return os.RemoveAll(dir) }, nil
+}
+
 // DirExists checks if a path exists and is a directory.
 func DirExists(path string, fs afero.Fs) (bool, error) {
 	return afero.DirExists(fs, path)
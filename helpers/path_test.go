@@ -64,6 +64,25 @@ func TestMakePath(t *testing.T) {
 	}
 }
 
+func TestMakePathTrimUnicodeWhitespace(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		input    string
+		expected string
+		trim     bool
+	}{
+		{"\u00a0Foo\u3000bar\u00a0", "Foo-bar", false},
+		{"\u00a0Foo\u3000bar\u00a0", "Foo-bar", true},
+		{"Foo\u200bbar", "Foobar", false},
+		{"Foo\u200bbar", "Foo-bar", true},
+		{"\ufeffFoo\u2060 bar\u180e", "Foo-bar", true},
+	} {
+		p := newTestPathSpec("trimUnicodeWhitespace", test.trim)
+		c.Assert(p.MakePath(test.input), qt.Equals, test.expected, qt.Commentf("%q", test.input))
+	}
+}
+
 func TestMakePathSanitized(t *testing.T) {
 	p := newTestPathSpec()
 
@@ -179,6 +198,8 @@ func TestMakeTitle(t *testing.T) {
 		{"Make-Title", "Make Title"},
 		{"MakeTitle", "MakeTitle"},
 		{"make_title", "make_title"},
+		{" \u00a0Make-Title\u3000\n", "Make Title"},
+		{"\u200bMake-Title\ufeff", "\u200bMake Title\ufeff"},
 	}
 	for i, d := range data {
 		output := helpers.MakeTitle(d.input)
@@ -515,3 +536,74 @@ func TestGetTempDir(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildTempDir(t *testing.T) {
+	c := qt.New(t)
+	parent := t.TempDir()
+
+	dir1, cleanup1, err := helpers.BuildTempDir(parent, "build1")
+	c.Assert(err, qt.IsNil)
+	dir2, cleanup2, err := helpers.BuildTempDir(parent, "build2")
+	c.Assert(err, qt.IsNil)
+	c.Assert(dir1, qt.Not(qt.Equals), dir2)
+	c.Assert(filepath.Dir(dir1), qt.Equals, parent)
+
+	dir1Again, _, err := helpers.BuildTempDir(parent, "build1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(dir1Again, qt.Equals, dir1)
+
+	c.Assert(os.MkdirAll(filepath.Join(dir1, "a", "b"), 0777), qt.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir1, "a", "b", "c.txt"), []byte("c"), 0666), qt.IsNil)
+
+	c.Assert(cleanup1(), qt.IsNil)
+	_, err = os.Stat(dir1)
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+	_, err = os.Stat(dir2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cleanup2(), qt.IsNil)
+
+	_, _, err = helpers.BuildTempDir(parent, "")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestSlugDeduper(t *testing.T) {
+	c := qt.New(t)
+
+	var d helpers.SlugDeduper
+	var got []string
+	for _, slug := range []string{"foo", "bar", "foo", "foo", "foo-1", "bar", "foo"} {
+		got = append(got, d.Unique(slug))
+	}
+	c.Assert(got, qt.DeepEquals, []string{"foo", "bar", "foo-1", "foo-2", "foo-1-1", "bar-1", "foo-3"})
+
+	// A literal slug colliding with an earlier generated one.
+	var d2 helpers.SlugDeduper
+	c.Assert(d2.Unique("a-1"), qt.Equals, "a-1")
+	c.Assert(d2.Unique("a"), qt.Equals, "a")
+	c.Assert(d2.Unique("a"), qt.Equals, "a-2")
+}
+
+func TestDateFromFilename(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name   string
+		expect time.Time
+		found  bool
+	}{
+		{"2023-05-16-hugo-rocks.md", time.Date(2023, 5, 16, 0, 0, 0, 0, time.UTC), true},
+		{filepath.FromSlash("content/posts/2023-05-16_hugo.md"), time.Date(2023, 5, 16, 0, 0, 0, 0, time.UTC), true},
+		{"2023-05-16.md", time.Date(2023, 5, 16, 0, 0, 0, 0, time.UTC), true},
+		{"2023-05-16", time.Date(2023, 5, 16, 0, 0, 0, 0, time.UTC), true},
+		{"hugo-rocks.md", time.Time{}, false},
+		{"2023-5-16-hugo.md", time.Time{}, false},
+		{"2023-02-30-hugo.md", time.Time{}, false},
+		{"2023-13-01-hugo.md", time.Time{}, false},
+		{"2023-05-161.md", time.Time{}, false},
+		{"", time.Time{}, false},
+	} {
+		d, found := helpers.DateFromFilename(test.name)
+		c.Assert(found, qt.Equals, test.found, qt.Commentf(test.name))
+		c.Assert(d, qt.Equals, test.expect, qt.Commentf(test.name))
+	}
+}
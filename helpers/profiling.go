@@ -0,0 +1,118 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+)
+
+// ProfileKinds lists the profile kinds supported by StartProfiling.
+var ProfileKinds = []string{"cpu", "mem", "mutex"}
+
+// StartProfiling starts profiling of the given kinds, see ProfileKinds,
+// writing each profile to <kind>.pprof in dir, which is created if needed.
+// The returned stop func stops the profiling and writes the profiles.
+func StartProfiling(dir string, kinds []string) (stop func() error, err error) {
+	var stops []func() error
+	stopAll := func() error {
+		var firstErr error
+		for _, stop := range stops {
+			if err := stop(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+	defer func() {
+		if err != nil {
+			stopAll()
+		}
+	}()
+
+	seen := make(map[string]bool)
+	for _, kind := range kinds {
+		if seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		switch kind {
+		case "cpu", "mem", "mutex":
+		default:
+			return nil, fmt.Errorf("unknown profile kind %q, must be one of %v", kind, ProfileKinds)
+		}
+	}
+
+	if len(seen) == 0 {
+		return func() error { return nil }, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return nil, fmt.Errorf("failed to create profile dir: %w", err)
+	}
+
+	create := func(kind string) (*os.File, error) {
+		f, err := os.Create(filepath.Join(dir, kind+".pprof"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s profile: %w", kind, err)
+		}
+		return f, nil
+	}
+
+	// Iterate in a fixed order, so the CPU profile is stopped first.
+	for _, kind := range ProfileKinds {
+		if !seen[kind] {
+			continue
+		}
+		f, err := create(kind)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "cpu":
+			if err := pprof.StartCPUProfile(f); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+			}
+			stops = append(stops, func() error {
+				pprof.StopCPUProfile()
+				return f.Close()
+			})
+		case "mem":
+			stops = append(stops, func() error {
+				defer f.Close()
+				runtime.GC() // get up-to-date statistics
+				if err := pprof.WriteHeapProfile(f); err != nil {
+					return fmt.Errorf("failed to write memory profile: %w", err)
+				}
+				return f.Close()
+			})
+		case "mutex":
+			prev := runtime.SetMutexProfileFraction(1)
+			stops = append(stops, func() error {
+				defer f.Close()
+				defer runtime.SetMutexProfileFraction(prev)
+				if err := pprof.Lookup("mutex").WriteTo(f, 0); err != nil {
+					return fmt.Errorf("failed to write mutex profile: %w", err)
+				}
+				return f.Close()
+			})
+		}
+	}
+
+	return stopAll, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestStartProfiling(t *testing.T) {
+	c := qt.New(t)
+
+	dir := filepath.Join(t.TempDir(), "profiles")
+
+	stop, err := helpers.StartProfiling(dir, []string{"cpu", "mem", "mutex", "cpu"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(stop(), qt.IsNil)
+
+	for _, kind := range []string{"cpu", "mem", "mutex"} {
+		fi, err := os.Stat(filepath.Join(dir, kind+".pprof"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(fi.Size() > 0, qt.IsTrue, qt.Commentf(kind))
+	}
+
+	// CPU profiling can be started again after stop.
+	dir2 := filepath.Join(t.TempDir(), "profiles")
+	stop, err = helpers.StartProfiling(dir2, []string{"cpu"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(stop(), qt.IsNil)
+	entries, err := os.ReadDir(dir2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 1)
+
+	_, err = helpers.StartProfiling(dir, []string{"cpu", "foo"})
+	c.Assert(err, qt.ErrorMatches, `unknown profile kind "foo".*`)
+
+	stop, err = helpers.StartProfiling(filepath.Join(t.TempDir(), "none"), nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stop(), qt.IsNil)
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const qrCodeMaxSize = 4096
+
+// QRCode encodes data as a QR code and returns it as a PNG image of
+// size x size pixels, including the quiet zone around the code.
+// The error correction level is one of "L" (7%), "M" (15%, the default
+// if empty), "Q" (25%) or "H" (30%).
+// An error is returned if size is too small to draw at least one pixel per module.
+func QRCode(data string, size int, level string) ([]byte, error) {
+	if data == "" {
+		return nil, errors.New("QR code data must be set")
+	}
+	if size <= 0 || size > qrCodeMaxSize {
+		return nil, fmt.Errorf("QR code size must be between 1 and %d, got %d", qrCodeMaxSize, size)
+	}
+
+	var recoveryLevel qrcode.RecoveryLevel
+	switch strings.ToUpper(level) {
+	case "L":
+		recoveryLevel = qrcode.Low
+	case "", "M":
+		recoveryLevel = qrcode.Medium
+	case "Q":
+		recoveryLevel = qrcode.High
+	case "H":
+		recoveryLevel = qrcode.Highest
+	default:
+		return nil, fmt.Errorf("unsupported QR code error correction level %q, use either L, M, Q or H", level)
+	}
+
+	q, err := qrcode.New(data, recoveryLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if modules := len(q.Bitmap()); size < modules {
+		return nil, fmt.Errorf("QR code size %d is too small, need at least %d pixels", size, modules)
+	}
+
+	return q.PNG(size)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/makiuchi-d/gozxing"
+	zxingqrcode "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+func TestQRCode(t *testing.T) {
+	c := qt.New(t)
+
+	for _, level := range []string{"L", "m", "Q", "H", ""} {
+		data := "https://gohugo.io/content-management/urls/?level=" + level
+		b, err := helpers.QRCode(data, 256, level)
+		c.Assert(err, qt.IsNil)
+
+		img, err := png.Decode(bytes.NewReader(b))
+		c.Assert(err, qt.IsNil)
+		c.Assert(img.Bounds().Dx(), qt.Equals, 256)
+		c.Assert(img.Bounds().Dy(), qt.Equals, 256)
+
+		bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+		c.Assert(err, qt.IsNil)
+		result, err := zxingqrcode.NewQRCodeReader().Decode(bmp, map[gozxing.DecodeHintType]any{gozxing.DecodeHintType_PURE_BARCODE: true})
+		c.Assert(err, qt.IsNil)
+		c.Assert(result.GetText(), qt.Equals, data)
+	}
+
+	_, err := helpers.QRCode("", 256, "M")
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = helpers.QRCode("https://gohugo.io/", 256, "X")
+	c.Assert(err, qt.ErrorMatches, `unsupported QR code error correction level "X".*`)
+	_, err = helpers.QRCode("https://gohugo.io/", 10, "M")
+	c.Assert(err, qt.ErrorMatches, `QR code size 10 is too small.*`)
+	_, err = helpers.QRCode("https://gohugo.io/", -1, "M")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RelatedIndex finds related pages by the keywords they have in common,
+// e.g. tags and categories.
+// The inverted keyword index and the ranked results are built on first use
+// and cached until the next call to Add.
+// It is safe for concurrent use.
+type RelatedIndex struct {
+	weights map[string]float64
+
+	mu   sync.Mutex
+	docs map[string]map[string][]string
+
+	// Built lazily, keyword set => keyword => page IDs.
+	index map[string]map[string][]string
+	// Page ID => related page IDs, best match first.
+	cache map[string][]string
+}
+
+// NewRelatedIndex creates a new RelatedIndex. weights maps a keyword set,
+// e.g. "tags", to the score given for every keyword in that set two pages
+// have in common. Keyword sets not in weights have a weight of 1.
+func NewRelatedIndex(weights map[string]float64) *RelatedIndex {
+	return &RelatedIndex{
+		weights: weights,
+		docs:    make(map[string]map[string][]string),
+	}
+}
+
+// Add adds or replaces the page with the given ID and its keywords, keyed by
+// keyword set, e.g. {"tags": {"go", "hugo"}, "categories": {"blog"}}.
+// Keywords are matched case insensitively.
+func (r *RelatedIndex) Add(id string, keywords map[string][]string) {
+	normalized := make(map[string][]string, len(keywords))
+	for set, kws := range keywords {
+		lower := make([]string, len(kws))
+		for i, kw := range kws {
+			lower[i] = strings.ToLower(kw)
+		}
+		normalized[set] = UniqueStrings(lower)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs[id] = normalized
+	r.index = nil
+	r.cache = nil
+}
+
+// Related returns the IDs of up to limit pages related to the page with the
+// given ID, the one with the most keywords in common first.
+// Ties are ordered by ID. Pages with no keywords in common are not included.
+// A limit <= 0 means no limit.
+func (r *RelatedIndex) Related(id string, limit int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.index == nil {
+		r.buildIndex()
+	}
+
+	related, found := r.cache[id]
+	if !found {
+		related = r.rank(id)
+		r.cache[id] = related
+	}
+
+	if limit > 0 && len(related) > limit {
+		related = related[:limit]
+	}
+
+	return append([]string(nil), related...)
+}
+
+func (r *RelatedIndex) buildIndex() {
+	r.index = make(map[string]map[string][]string)
+	r.cache = make(map[string][]string)
+	for id, sets := range r.docs {
+		for set, kws := range sets {
+			m, found := r.index[set]
+			if !found {
+				m = make(map[string][]string)
+				r.index[set] = m
+			}
+			for _, kw := range kws {
+				m[kw] = append(m[kw], id)
+			}
+		}
+	}
+}
+
+func (r *RelatedIndex) rank(id string) []string {
+	scores := make(map[string]float64)
+	for set, kws := range r.docs[id] {
+		weight, found := r.weights[set]
+		if !found {
+			weight = 1
+		}
+		for _, kw := range kws {
+			for _, other := range r.index[set][kw] {
+				if other != id {
+					scores[other] += weight
+				}
+			}
+		}
+	}
+
+	related := make([]string, 0, len(scores))
+	for other, score := range scores {
+		if score > 0 {
+			related = append(related, other)
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		si, sj := scores[related[i]], scores[related[j]]
+		if si != sj {
+			return si > sj
+		}
+		return related[i] < related[j]
+	})
+
+	return related
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestRelatedIndex(t *testing.T) {
+	c := qt.New(t)
+
+	newIndex := func(weights map[string]float64) *helpers.RelatedIndex {
+		r := helpers.NewRelatedIndex(weights)
+		r.Add("p1", map[string][]string{"tags": {"go", "hugo", "templates"}, "categories": {"blog"}})
+		r.Add("p2", map[string][]string{"tags": {"Go", "hugo", "templates"}})
+		r.Add("p3", map[string][]string{"tags": {"go"}, "categories": {"blog"}})
+		r.Add("p4", map[string][]string{"tags": {"go"}})
+		r.Add("p5", map[string][]string{"tags": {"rust"}, "categories": {"news"}})
+		return r
+	}
+
+	r := newIndex(nil)
+	c.Assert(r.Related("p1", 0), qt.DeepEquals, []string{"p2", "p3", "p4"})
+	c.Assert(r.Related("p1", 2), qt.DeepEquals, []string{"p2", "p3"})
+	c.Assert(r.Related("p1", 1), qt.DeepEquals, []string{"p2"})
+	c.Assert(r.Related("p4", 0), qt.DeepEquals, []string{"p1", "p2", "p3"})
+	c.Assert(r.Related("p5", 0), qt.HasLen, 0)
+	c.Assert(r.Related("nope", 0), qt.HasLen, 0)
+
+	// A category in common now outweighs two tags.
+	r = newIndex(map[string]float64{"tags": 1, "categories": 5})
+	c.Assert(r.Related("p1", 0), qt.DeepEquals, []string{"p3", "p2", "p4"})
+
+	// Adding a page invalidates the cached results.
+	r.Add("p6", map[string][]string{"tags": {"go", "hugo", "templates"}, "categories": {"blog"}})
+	c.Assert(r.Related("p1", 2), qt.DeepEquals, []string{"p6", "p3"})
+}
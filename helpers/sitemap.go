@@ -0,0 +1,123 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// sitemapPriorityHalfLife is the age at which a page's recency score is halved.
+const sitemapPriorityHalfLife = 180 * 24 * time.Hour
+
+// SitemapPriority returns a sitemap priority in the range [0.0, 1.0] for a page
+// at the given depth in the site tree (0 for the home page, 1 for a top level
+// section etc.) last modified at lastmod:
+//
+//	priority = 0.6 * 1/(1+depth) + 0.4 * 0.5^(age/180 days)
+//
+// where age is now - lastmod. That is, the depth score halves for the first
+// level and then falls off slowly, and the recency score halves every 180 days.
+// A zero lastmod gives a recency score of 0, and a lastmod after now is treated
+// as now.
+func SitemapPriority(depth int, lastmod, now time.Time) float64 {
+	if depth < 0 {
+		depth = 0
+	}
+	depthScore := 1 / float64(1+depth)
+
+	var recencyScore float64
+	if !lastmod.IsZero() {
+		age := now.Sub(lastmod)
+		if age < 0 {
+			age = 0
+		}
+		recencyScore = math.Pow(0.5, float64(age)/float64(sitemapPriorityHalfLife))
+	}
+
+	priority := 0.6*depthScore + 0.4*recencyScore
+
+	return math.Max(0, math.Min(1, priority))
+}
+
+// NewsMeta holds the publication metadata for a Google News sitemap entry, see
+// https://developers.google.com/search/docs/crawling-indexing/sitemaps/news-sitemap
+type NewsMeta struct {
+	// The name of the news publication, e.g. "The Example Times". Required.
+	PublicationName string
+	// The ISO 639 language code of the publication, e.g. "en", or "zh-cn" or
+	// "zh-tw" for Chinese. Required.
+	Language string
+	// The date the article was first published. Required.
+	PublicationDate time.Time
+	// The title of the article. Required.
+	Title string
+}
+
+// NewsSitemapEntry returns the <news:news> XML fragment for meta, to be placed
+// inside a sitemap <url> element. The sitemap's urlset must declare the
+// xmlns:news="http://www.google.com/schemas/sitemap-news/0.9" namespace.
+func NewsSitemapEntry(meta NewsMeta) (string, error) {
+	if strings.TrimSpace(meta.PublicationName) == "" {
+		return "", errors.New("news sitemap: publication name must be set")
+	}
+	if strings.TrimSpace(meta.Title) == "" {
+		return "", errors.New("news sitemap: title must be set")
+	}
+	if meta.PublicationDate.IsZero() {
+		return "", errors.New("news sitemap: publication date must be set")
+	}
+	lang := strings.ToLower(meta.Language)
+	if !isNewsSitemapLanguage(lang) {
+		return "", fmt.Errorf("news sitemap: invalid language %q, must be an ISO 639 code, e.g. en, or zh-cn or zh-tw", meta.Language)
+	}
+
+	var b bytes.Buffer
+	writeElement := func(indent, name, value string) {
+		b.WriteString(indent + "<" + name + ">")
+		xml.EscapeText(&b, []byte(value))
+		b.WriteString("</" + name + ">\n")
+	}
+
+	b.WriteString("<news:news>\n")
+	b.WriteString("  <news:publication>\n")
+	writeElement("    ", "news:name", meta.PublicationName)
+	writeElement("    ", "news:language", lang)
+	b.WriteString("  </news:publication>\n")
+	writeElement("  ", "news:publication_date", meta.PublicationDate.Format(time.RFC3339))
+	writeElement("  ", "news:title", meta.Title)
+	b.WriteString("</news:news>")
+
+	return b.String(), nil
+}
+
+func isNewsSitemapLanguage(lang string) bool {
+	if lang == "zh-cn" || lang == "zh-tw" {
+		return true
+	}
+	if len(lang) < 2 || len(lang) > 3 {
+		return false
+	}
+	for _, r := range lang {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
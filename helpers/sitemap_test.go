@@ -0,0 +1,96 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestSitemapPriority(t *testing.T) {
+	c := qt.New(t)
+
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := now.Add(-time.Hour)
+	stale := now.AddDate(-5, 0, 0)
+
+	home := helpers.SitemapPriority(0, fresh, now)
+	section := helpers.SitemapPriority(1, fresh, now)
+	deepFresh := helpers.SitemapPriority(4, fresh, now)
+	deepStale := helpers.SitemapPriority(4, stale, now)
+
+	c.Assert(home, qt.Satisfies, func(v float64) bool { return v > 0.99 && v <= 1 })
+	c.Assert(home > section, qt.IsTrue)
+	c.Assert(section > deepFresh, qt.IsTrue)
+	c.Assert(deepFresh > deepStale, qt.IsTrue)
+	c.Assert(deepStale, qt.Satisfies, func(v float64) bool { return v >= 0 && v < 0.15 })
+
+	// The recency score halves every 180 days.
+	halfLife := helpers.SitemapPriority(0, now.Add(-180*24*time.Hour), now)
+	c.Assert(halfLife, qt.Satisfies, func(v float64) bool { return v > 0.79 && v < 0.81 })
+
+	// Edge cases.
+	c.Assert(helpers.SitemapPriority(0, now.Add(time.Hour), now), qt.Equals, 1.0)
+	c.Assert(helpers.SitemapPriority(-1, time.Time{}, now), qt.Equals, 0.6)
+	c.Assert(helpers.SitemapPriority(100, time.Time{}, now) > 0, qt.IsTrue)
+}
+
+func TestNewsSitemapEntry(t *testing.T) {
+	c := qt.New(t)
+
+	meta := helpers.NewsMeta{
+		PublicationName: "The Example Times",
+		Language:        "EN",
+		PublicationDate: time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC),
+		Title:           "Companies A & B in Merger Talks",
+	}
+
+	s, err := helpers.NewsSitemapEntry(meta)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s, qt.Equals, `<news:news>
+  <news:publication>
+    <news:name>The Example Times</news:name>
+    <news:language>en</news:language>
+  </news:publication>
+  <news:publication_date>2023-06-01T12:30:00Z</news:publication_date>
+  <news:title>Companies A &amp; B in Merger Talks</news:title>
+</news:news>`)
+
+	zh := meta
+	zh.Language = "zh-TW"
+	s, err = helpers.NewsSitemapEntry(zh)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s, qt.Contains, "<news:language>zh-tw</news:language>")
+
+	for _, lang := range []string{"", "english", "en-US", "e1"} {
+		invalid := meta
+		invalid.Language = lang
+		_, err = helpers.NewsSitemapEntry(invalid)
+		c.Assert(err, qt.ErrorMatches, ".*invalid language.*", qt.Commentf(lang))
+	}
+
+	for _, modify := range []func(m *helpers.NewsMeta){
+		func(m *helpers.NewsMeta) { m.PublicationName = " " },
+		func(m *helpers.NewsMeta) { m.Title = "" },
+		func(m *helpers.NewsMeta) { m.PublicationDate = time.Time{} },
+	} {
+		invalid := meta
+		modify(&invalid)
+		_, err = helpers.NewsSitemapEntry(invalid)
+		c.Assert(err, qt.ErrorMatches, ".*must be set")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TimingEntry holds the timings observed for a name, e.g. a template.
+type TimingEntry struct {
+	Name  string
+	Count int
+	Max   time.Duration
+	Total time.Duration
+}
+
+// SlowLog keeps track of the slowest observations, e.g. template executions.
+// It's safe for concurrent use.
+type SlowLog struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*TimingEntry
+}
+
+// NewSlowLog creates a new SlowLog that keeps timings for at most capacity
+// names. When full, the name with the lowest total is evicted to make room
+// for a new one. A capacity <= 0 means no limit.
+func NewSlowLog(capacity int) *SlowLog {
+	return &SlowLog{capacity: capacity, entries: make(map[string]*TimingEntry)}
+}
+
+// Observe records that name took d.
+func (l *SlowLog) Observe(name string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, found := l.entries[name]
+	if !found {
+		if l.capacity > 0 && len(l.entries) >= l.capacity {
+			l.evict()
+		}
+		e = &TimingEntry{Name: name}
+		l.entries[name] = e
+	}
+	e.Count++
+	e.Total += d
+	if d > e.Max {
+		e.Max = d
+	}
+}
+
+// evict removes the entry with the lowest total.
+// l must be locked.
+func (l *SlowLog) evict() {
+	var lowest *TimingEntry
+	for _, e := range l.entries {
+		if lowest == nil || e.Total < lowest.Total || (e.Total == lowest.Total && e.Name > lowest.Name) {
+			lowest = e
+		}
+	}
+	if lowest != nil {
+		delete(l.entries, lowest.Name)
+	}
+}
+
+// Slowest returns the n entries with the highest max duration, slowest first.
+// Ties are broken by total and then by name.
+func (l *SlowLog) Slowest(n int) []TimingEntry {
+	return l.sorted(n, func(a, b TimingEntry) bool {
+		if a.Max != b.Max {
+			return a.Max > b.Max
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Name < b.Name
+	})
+}
+
+// SlowestByTotal returns the n entries with the highest total duration,
+// slowest first. Ties are broken by max and then by name.
+func (l *SlowLog) SlowestByTotal(n int) []TimingEntry {
+	return l.sorted(n, func(a, b TimingEntry) bool {
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.Max != b.Max {
+			return a.Max > b.Max
+		}
+		return a.Name < b.Name
+	})
+}
+
+func (l *SlowLog) sorted(n int, less func(a, b TimingEntry) bool) []TimingEntry {
+	l.mu.Lock()
+	entries := make([]TimingEntry, 0, len(l.entries))
+	for _, e := range l.entries {
+		entries = append(entries, *e)
+	}
+	l.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+	if n >= 0 && n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestSlowLog(t *testing.T) {
+	c := qt.New(t)
+
+	l := helpers.NewSlowLog(0)
+	l.Observe("a.html", 10*time.Millisecond)
+	l.Observe("a.html", 10*time.Millisecond)
+	l.Observe("a.html", 10*time.Millisecond)
+	l.Observe("b.html", 25*time.Millisecond)
+	l.Observe("c.html", 25*time.Millisecond)
+	l.Observe("d.html", 5*time.Millisecond)
+	l.Observe("e.html", 25*time.Millisecond)
+	l.Observe("e.html", 1*time.Millisecond)
+
+	names := func(entries []helpers.TimingEntry) []string {
+		var s []string
+		for _, e := range entries {
+			s = append(s, e.Name)
+		}
+		return s
+	}
+
+	c.Assert(names(l.Slowest(3)), qt.DeepEquals, []string{"e.html", "b.html", "c.html"})
+	c.Assert(names(l.SlowestByTotal(3)), qt.DeepEquals, []string{"a.html", "e.html", "b.html"})
+	c.Assert(l.Slowest(-1), qt.HasLen, 5)
+	c.Assert(l.Slowest(0), qt.HasLen, 0)
+	c.Assert(l.Slowest(1)[0], qt.DeepEquals, helpers.TimingEntry{Name: "e.html", Count: 2, Max: 25 * time.Millisecond, Total: 26 * time.Millisecond})
+}
+
+func TestSlowLogCapacity(t *testing.T) {
+	c := qt.New(t)
+
+	l := helpers.NewSlowLog(2)
+	l.Observe("a.html", 3*time.Millisecond)
+	l.Observe("b.html", 1*time.Millisecond)
+	l.Observe("c.html", 2*time.Millisecond)
+	l.Observe("d.html", 1*time.Millisecond)
+
+	entries := l.Slowest(10)
+	c.Assert(entries, qt.HasLen, 2)
+	c.Assert(entries[0].Name, qt.Equals, "a.html")
+	c.Assert(entries[1].Name, qt.Equals, "d.html")
+}
+
+func TestSlowLogConcurrent(t *testing.T) {
+	c := qt.New(t)
+
+	l := helpers.NewSlowLog(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Observe(fmt.Sprintf("t%d.html", j%20), time.Duration(i+1)*time.Millisecond)
+				l.Slowest(3)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries := l.SlowestByTotal(-1)
+	c.Assert(entries, qt.HasLen, 20)
+	for _, e := range entries {
+		c.Assert(e.Count, qt.Equals, 50)
+		c.Assert(e.Max, qt.Equals, 10*time.Millisecond)
+	}
+}
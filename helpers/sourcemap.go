@@ -0,0 +1,319 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sourceMap is a version 3 source map, see https://sourcemaps.info/spec.html
+type sourceMap struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+}
+
+// sourceMapSegment maps a position in the generated file to a position in
+// one of the sources. All values are 0-based; source and name are -1 if not set.
+type sourceMapSegment struct {
+	genCol  int
+	source  int
+	srcLine int
+	srcCol  int
+	name    int
+}
+
+// MergeSourceMaps composes the source maps of a chain of transformations,
+// e.g. transpile and then minify, into one that maps the final output back to
+// the original sources. The maps are given in the order the transformations
+// were applied, so the sources of every map after the first must be the file
+// generated by the map before it, which means they must have exactly one source.
+// Positions in the final output that can't be traced back to the original
+// sources are left unmapped.
+func MergeSourceMaps(maps ...[]byte) ([]byte, error) {
+	if len(maps) == 0 {
+		return nil, errors.New("no source maps to merge")
+	}
+
+	parsed := make([]*sourceMap, len(maps))
+	lines := make([][][]sourceMapSegment, len(maps))
+	for i, b := range maps {
+		var sm sourceMap
+		if err := json.Unmarshal(b, &sm); err != nil {
+			return nil, fmt.Errorf("source map %d: %w", i+1, err)
+		}
+		if sm.Version != 3 {
+			return nil, fmt.Errorf("source map %d: unsupported version %d", i+1, sm.Version)
+		}
+		if i > 0 && len(sm.Sources) != 1 {
+			return nil, fmt.Errorf("source map %d: expected exactly 1 source, got %d", i+1, len(sm.Sources))
+		}
+		decoded, err := decodeSourceMapMappings(&sm)
+		if err != nil {
+			return nil, fmt.Errorf("source map %d: %w", i+1, err)
+		}
+		parsed[i], lines[i] = &sm, decoded
+	}
+
+	first, last := parsed[0], parsed[len(parsed)-1]
+	result := &sourceMap{
+		Version:    3,
+		File:       last.File,
+		SourceRoot: first.SourceRoot,
+		Sources:    []string{},
+		Names:      []string{},
+	}
+	sourceIndex := make(map[int]int)
+	nameIndex := make(map[string]int)
+	var hasContent bool
+
+	merged := make([][]sourceMapSegment, len(lines[len(lines)-1]))
+	for genLine, segments := range lines[len(lines)-1] {
+		for _, seg := range segments {
+			if seg.source == -1 {
+				continue
+			}
+			name := ""
+			if seg.name != -1 {
+				name = last.Names[seg.name]
+			}
+
+			// Trace the position back through the earlier maps.
+			srcLine, srcCol, source, ok := seg.srcLine, seg.srcCol, seg.source, true
+			for i := len(lines) - 2; i >= 0 && ok; i-- {
+				var prev sourceMapSegment
+				prev, ok = findSourceMapSegment(lines[i], srcLine, srcCol)
+				if !ok || prev.source == -1 {
+					ok = false
+					break
+				}
+				srcLine, srcCol, source = prev.srcLine, prev.srcCol, prev.source
+				if prev.name != -1 {
+					name = parsed[i].Names[prev.name]
+				}
+			}
+			if !ok {
+				continue
+			}
+
+			idx, found := sourceIndex[source]
+			if !found {
+				idx = len(result.Sources)
+				sourceIndex[source] = idx
+				result.Sources = append(result.Sources, first.Sources[source])
+				var content *string
+				if source < len(first.SourcesContent) {
+					content = first.SourcesContent[source]
+					hasContent = hasContent || content != nil
+				}
+				result.SourcesContent = append(result.SourcesContent, content)
+			}
+
+			out := sourceMapSegment{genCol: seg.genCol, source: idx, srcLine: srcLine, srcCol: srcCol, name: -1}
+			if name != "" {
+				ni, found := nameIndex[name]
+				if !found {
+					ni = len(result.Names)
+					nameIndex[name] = ni
+					result.Names = append(result.Names, name)
+				}
+				out.name = ni
+			}
+			merged[genLine] = append(merged[genLine], out)
+		}
+	}
+
+	if !hasContent {
+		result.SourcesContent = nil
+	}
+	result.Mappings = encodeSourceMapMappings(merged)
+
+	return json.Marshal(result)
+}
+
+// findSourceMapSegment returns the segment on the given generated line with
+// the largest column <= col.
+func findSourceMapSegment(lines [][]sourceMapSegment, line, col int) (sourceMapSegment, bool) {
+	if line < 0 || line >= len(lines) {
+		return sourceMapSegment{}, false
+	}
+	segments := lines[line]
+	i := sort.Search(len(segments), func(i int) bool {
+		return segments[i].genCol > col
+	})
+	if i == 0 {
+		return sourceMapSegment{}, false
+	}
+	return segments[i-1], true
+}
+
+// decodeSourceMapMappings decodes the Base64 VLQ encoded mappings of sm.
+// It returns an error if a segment refers to a source or name not in sm, or
+// to a negative line or column.
+func decodeSourceMapMappings(sm *sourceMap) ([][]sourceMapSegment, error) {
+	var (
+		lines                   [][]sourceMapSegment
+		source, srcLine, srcCol int
+		name                    int
+	)
+
+	for i, line := range strings.Split(sm.Mappings, ";") {
+		var segments []sourceMapSegment
+		genCol := 0
+		for _, field := range strings.Split(line, ",") {
+			if field == "" {
+				continue
+			}
+			values, err := decodeVLQ(field)
+			if err != nil {
+				return nil, err
+			}
+			seg := sourceMapSegment{source: -1, name: -1}
+			switch len(values) {
+			case 1, 4, 5:
+			default:
+				return nil, fmt.Errorf("invalid mapping segment %q", field)
+			}
+			genCol += values[0]
+			if genCol < 0 {
+				return nil, fmt.Errorf("line %d: negative column %d", i+1, genCol)
+			}
+			seg.genCol = genCol
+			if len(values) >= 4 {
+				source += values[1]
+				srcLine += values[2]
+				srcCol += values[3]
+				switch {
+				case source < 0 || source >= len(sm.Sources):
+					return nil, fmt.Errorf("line %d: source index %d out of range", i+1, source)
+				case srcLine < 0:
+					return nil, fmt.Errorf("line %d: negative source line %d", i+1, srcLine)
+				case srcCol < 0:
+					return nil, fmt.Errorf("line %d: negative source column %d", i+1, srcCol)
+				}
+				seg.source, seg.srcLine, seg.srcCol = source, srcLine, srcCol
+			}
+			if len(values) == 5 {
+				name += values[4]
+				if name < 0 || name >= len(sm.Names) {
+					return nil, fmt.Errorf("line %d: name index %d out of range", i+1, name)
+				}
+				seg.name = name
+			}
+			segments = append(segments, seg)
+		}
+		sort.SliceStable(segments, func(i, j int) bool {
+			return segments[i].genCol < segments[j].genCol
+		})
+		lines = append(lines, segments)
+	}
+
+	return lines, nil
+}
+
+// encodeSourceMapMappings encodes mappings with Base64 VLQ.
+func encodeSourceMapMappings(lines [][]sourceMapSegment) string {
+	var (
+		b                       strings.Builder
+		source, srcLine, srcCol int
+		name                    int
+	)
+
+	for i, segments := range lines {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		genCol := 0
+		for j, seg := range segments {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(encodeVLQ(seg.genCol - genCol))
+			genCol = seg.genCol
+			if seg.source == -1 {
+				continue
+			}
+			b.WriteString(encodeVLQ(seg.source - source))
+			b.WriteString(encodeVLQ(seg.srcLine - srcLine))
+			b.WriteString(encodeVLQ(seg.srcCol - srcCol))
+			source, srcLine, srcCol = seg.source, seg.srcLine, seg.srcCol
+			if seg.name != -1 {
+				b.WriteString(encodeVLQ(seg.name - name))
+				name = seg.name
+			}
+		}
+	}
+
+	return b.String()
+}
+
+const base64VLQChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// decodeVLQ decodes the Base64 VLQ values in s.
+func decodeVLQ(s string) ([]int, error) {
+	var values []int
+	var value, shift int
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base64VLQChars, s[i])
+		if digit == -1 {
+			return nil, fmt.Errorf("invalid Base64 VLQ character %q", s[i])
+		}
+		value |= (digit & 0x1f) << shift
+		if digit&0x20 != 0 {
+			shift += 5
+			if shift > 30 {
+				return nil, errors.New("Base64 VLQ value overflows")
+			}
+			continue
+		}
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+	if shift != 0 {
+		return nil, fmt.Errorf("truncated Base64 VLQ value in %q", s)
+	}
+	return values, nil
+}
+
+// encodeVLQ encodes v as Base64 VLQ.
+func encodeVLQ(v int) string {
+	u := v << 1
+	if v < 0 {
+		u = (-v << 1) | 1
+	}
+	var b []byte
+	for {
+		digit := u & 0x1f
+		u >>= 5
+		if u > 0 {
+			digit |= 0x20
+		}
+		b = append(b, base64VLQChars[digit])
+		if u == 0 {
+			return string(b)
+		}
+	}
+}
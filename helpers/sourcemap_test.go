@@ -0,0 +1,90 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestMergeSourceMaps(t *testing.T) {
+	c := qt.New(t)
+
+	// main.ts => main.js:
+	//   main.js 1:0 => main.ts 1:0
+	//   main.js 1:4 => main.ts 2:2 (foo)
+	//   main.js 2:2 => main.ts 4:0
+	transpiled := `{"version":3,"file":"main.js","sources":["main.ts"],"sourcesContent":["let x = 1;"],"names":["foo"],"mappings":"AAAA,IACEA;EAEF"}`
+	// main.js => main.min.js:
+	//   main.min.js 1:0  => main.js 1:0
+	//   main.min.js 1:10 => main.js 1:6
+	//   main.min.js 1:20 => main.js 2:5
+	minified := `{"version":3,"file":"main.min.js","sources":["main.js"],"names":[],"mappings":"AAAA,UAAM,UACD"}`
+
+	b, err := helpers.MergeSourceMaps([]byte(transpiled), []byte(minified))
+	c.Assert(err, qt.IsNil)
+
+	var m map[string]any
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+	c.Assert(m["version"], qt.Equals, 3.0)
+	c.Assert(m["file"], qt.Equals, "main.min.js")
+	c.Assert(m["sources"], qt.DeepEquals, []any{"main.ts"})
+	c.Assert(m["sourcesContent"], qt.DeepEquals, []any{"let x = 1;"})
+	c.Assert(m["names"], qt.DeepEquals, []any{"foo"})
+	// main.min.js 1:0 => main.ts 1:0, 1:10 => main.ts 2:2 (foo), 1:20 => main.ts 4:0
+	c.Assert(m["mappings"], qt.Equals, "AAAA,UACEA,UAEF")
+
+	// A single map is returned as is (normalized).
+	b, err = helpers.MergeSourceMaps([]byte(transpiled))
+	c.Assert(err, qt.IsNil)
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+	c.Assert(m["mappings"], qt.Equals, "AAAA,IACEA;EAEF")
+
+	// Large values.
+	b, err = helpers.MergeSourceMaps([]byte(`{"version":3,"sources":["a.js"],"names":[],"mappings":"w+BAAw+B"}`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+	c.Assert(m["mappings"], qt.Equals, "w+BAAw+B")
+
+	_, err = helpers.MergeSourceMaps()
+	c.Assert(err, qt.IsNotNil)
+	_, err = helpers.MergeSourceMaps([]byte(`{"version":2}`))
+	c.Assert(err, qt.ErrorMatches, ".*unsupported version 2")
+	_, err = helpers.MergeSourceMaps([]byte(transpiled), []byte(`{"version":3,"sources":["a.js","b.js"],"mappings":""}`))
+	c.Assert(err, qt.ErrorMatches, ".*expected exactly 1 source, got 2")
+	_, err = helpers.MergeSourceMaps([]byte(`{"version":3,"sources":["a.js"],"mappings":"AA!A"}`))
+	c.Assert(err, qt.ErrorMatches, ".*invalid Base64 VLQ character.*")
+
+	// Malformed maps with indices out of range.
+	for _, test := range []struct {
+		mappings string
+		expect   string
+	}{
+		{"D", `source map 1: line 1: negative column -1`},
+		{"ACAA", `source map 1: line 1: source index 1 out of range`},
+		{"ADAA", `source map 1: line 1: source index -1 out of range`},
+		{"AADA", `source map 1: line 1: negative source line -1`},
+		{"AAAD", `source map 1: line 1: negative source column -1`},
+		{"AAAAA;AAAAC", `source map 1: line 2: name index 1 out of range`},
+		{"AAAAD", `source map 1: line 1: name index -1 out of range`},
+	} {
+		_, err = helpers.MergeSourceMaps([]byte(`{"version":3,"sources":["a.js"],"names":["foo"],"mappings":"` + test.mappings + `"}`))
+		c.Assert(err, qt.ErrorMatches, test.expect, qt.Commentf(test.mappings))
+	}
+	_, err = helpers.MergeSourceMaps([]byte(transpiled), []byte(`{"version":3,"sources":["main.js"],"names":[],"mappings":"ACAA"}`))
+	c.Assert(err, qt.ErrorMatches, `source map 2: line 1: source index 1 out of range`)
+}
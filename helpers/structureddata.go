@@ -0,0 +1,117 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ArticleMeta holds the metadata used to build a schema.org Article.
+type ArticleMeta struct {
+	// The schema.org type, "Article" (default) or "BlogPosting".
+	Type string
+
+	Headline    string
+	Description string
+	URL         string
+
+	AuthorName string
+	AuthorURL  string
+
+	DatePublished time.Time
+	DateModified  time.Time
+
+	Images []string
+
+	PublisherName string
+	PublisherLogo string
+}
+
+// ArticleJSONLD returns a schema.org Article or BlogPosting as JSON-LD.
+// Empty fields are omitted and the keys are sorted, so the output is stable.
+// See https://schema.org/Article
+func ArticleJSONLD(meta ArticleMeta) ([]byte, error) {
+	typ := meta.Type
+	switch typ {
+	case "":
+		typ = "Article"
+	case "Article", "BlogPosting":
+	default:
+		return nil, fmt.Errorf("unsupported article type %q, use either Article or BlogPosting", typ)
+	}
+
+	if meta.Headline == "" {
+		return nil, errors.New("article headline must be set")
+	}
+
+	m := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    typ,
+		"headline": meta.Headline,
+	}
+
+	setIfNotEmpty := func(m map[string]any, key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	setIfNotEmpty(m, "description", meta.Description)
+	setIfNotEmpty(m, "url", meta.URL)
+
+	if !meta.DatePublished.IsZero() {
+		m["datePublished"] = meta.DatePublished.Format(time.RFC3339)
+	}
+	if !meta.DateModified.IsZero() {
+		m["dateModified"] = meta.DateModified.Format(time.RFC3339)
+	}
+
+	if meta.AuthorName != "" {
+		author := map[string]any{
+			"@type": "Person",
+			"name":  meta.AuthorName,
+		}
+		setIfNotEmpty(author, "url", meta.AuthorURL)
+		m["author"] = author
+	}
+
+	var images []string
+	for _, image := range meta.Images {
+		if image != "" {
+			images = append(images, image)
+		}
+	}
+	if len(images) > 0 {
+		m["image"] = images
+	}
+
+	if meta.PublisherName != "" {
+		publisher := map[string]any{
+			"@type": "Organization",
+			"name":  meta.PublisherName,
+		}
+		if meta.PublisherLogo != "" {
+			publisher["logo"] = map[string]any{
+				"@type": "ImageObject",
+				"url":   meta.PublisherLogo,
+			}
+		}
+		m["publisher"] = publisher
+	}
+
+	return json.Marshal(m)
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestArticleJSONLD(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := helpers.ArticleJSONLD(helpers.ArticleMeta{
+		Type:          "BlogPosting",
+		Headline:      "Hugo Rocks",
+		AuthorName:    "Jane Doe",
+		DatePublished: time.Date(2023, 5, 16, 10, 0, 0, 0, time.UTC),
+		Images:        []string{"https://example.org/a.jpg", ""},
+		PublisherName: "Hugo",
+		PublisherLogo: "https://example.org/logo.png",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"@context":"https://schema.org","@type":"BlogPosting","author":{"@type":"Person","name":"Jane Doe"},"datePublished":"2023-05-16T10:00:00Z","headline":"Hugo Rocks","image":["https://example.org/a.jpg"],"publisher":{"@type":"Organization","logo":{"@type":"ImageObject","url":"https://example.org/logo.png"},"name":"Hugo"}}`)
+
+	b, err = helpers.ArticleJSONLD(helpers.ArticleMeta{Headline: "Minimal"})
+	c.Assert(err, qt.IsNil)
+	var m map[string]any
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+	c.Assert(m, qt.DeepEquals, map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "Article",
+		"headline": "Minimal",
+	})
+
+	_, err = helpers.ArticleJSONLD(helpers.ArticleMeta{})
+	c.Assert(err, qt.ErrorMatches, "article headline must be set")
+	_, err = helpers.ArticleJSONLD(helpers.ArticleMeta{Type: "Recipe", Headline: "Foo"})
+	c.Assert(err, qt.ErrorMatches, `unsupported article type "Recipe".*`)
+}
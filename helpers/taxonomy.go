@@ -0,0 +1,139 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TermOptions configures NormalizeTerm.
+type TermOptions struct {
+	// Lower cases the term, e.g. "Go Lang" => "go lang".
+	// Use this for the term's key, and leave it off to get the display form.
+	Lowercase bool
+
+	// Replaces any run of whitespace inside the term with a single space,
+	// e.g. "go \t lang" => "go lang".
+	CollapseWhitespace bool
+}
+
+// NormalizeTerm normalizes the taxonomy term s according to opts, so that
+// e.g. "  Go Lang " and "go lang" map to the same term page.
+// Leading and trailing whitespace is always removed.
+func NormalizeTerm(s string, opts TermOptions) string {
+	s = strings.TrimSpace(s)
+
+	if opts.CollapseWhitespace {
+		s = strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+	}
+
+	if opts.Lowercase {
+		s = strings.ToLower(s)
+	}
+
+	return s
+}
+
+// TermEntry is a taxonomy term with its count or weight.
+type TermEntry struct {
+	Name  string
+	Value int
+}
+
+// OrderTerms returns the terms, a map from term name to count or weight,
+// ordered by one of:
+//
+// - "count": highest count first.
+// - "weight": lowest weight first, with the unweighted (zero) terms last.
+// - "alphabetical" (the default): by name.
+//
+// Ties are ordered by name.
+func OrderTerms(terms map[string]int, by string) []TermEntry {
+	entries := make([]TermEntry, 0, len(terms))
+	for name, value := range terms {
+		entries = append(entries, TermEntry{Name: name, Value: value})
+	}
+
+	var less func(a, b TermEntry) bool
+	switch strings.ToLower(by) {
+	case "count":
+		less = func(a, b TermEntry) bool {
+			return a.Value > b.Value
+		}
+	case "weight":
+		less = func(a, b TermEntry) bool {
+			if a.Value == 0 || b.Value == 0 {
+				return b.Value == 0 && a.Value != 0
+			}
+			return a.Value < b.Value
+		}
+	default:
+		less = func(a, b TermEntry) bool {
+			return false
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Name < b.Name
+	})
+
+	return entries
+}
+
+// TagCloudScale maps each term's count to a font size in the range
+// [minSize, maxSize], e.g. for a tag cloud.
+// The sizes follow a logarithmic scale, so a few very popular terms don't
+// dwarf the rest. If all terms have the same count, they all get the midpoint
+// of the range. Counts below 1 are treated as 1.
+func TagCloudScale(counts map[string]int, minSize, maxSize int) map[string]int {
+	sizes := make(map[string]int, len(counts))
+	if len(counts) == 0 {
+		return sizes
+	}
+
+	logCount := func(count int) float64 {
+		if count < 1 {
+			count = 1
+		}
+		return math.Log(float64(count))
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, count := range counts {
+		v := logCount(count)
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	for term, count := range counts {
+		if hi == lo {
+			sizes[term] = minSize + (maxSize-minSize)/2
+			continue
+		}
+		f := (logCount(count) - lo) / (hi - lo)
+		sizes[term] = minSize + int(math.Round(f*float64(maxSize-minSize)))
+	}
+
+	return sizes
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestNormalizeTerm(t *testing.T) {
+	c := qt.New(t)
+
+	key := helpers.TermOptions{Lowercase: true, CollapseWhitespace: true}
+	display := helpers.TermOptions{CollapseWhitespace: true}
+
+	for _, test := range []struct {
+		in     string
+		opts   helpers.TermOptions
+		expect string
+	}{
+		{"  Go Lang ", key, "go lang"},
+		{"go lang", key, "go lang"},
+		{"\tGo \n  Lang ", key, "go lang"},
+		{"  Go   Lang ", display, "Go Lang"},
+		{"  Go   Lang ", helpers.TermOptions{Lowercase: true}, "go   lang"},
+		{"  Go   Lang ", helpers.TermOptions{}, "Go   Lang"},
+		{"Ærø", key, "ærø"},
+		{"   ", key, ""},
+	} {
+		c.Assert(helpers.NormalizeTerm(test.in, test.opts), qt.Equals, test.expect, qt.Commentf("%q", test.in))
+	}
+
+	// Both forms of the same term share the key.
+	c.Assert(helpers.NormalizeTerm("  Go Lang ", key), qt.Equals, helpers.NormalizeTerm("go  lang", key))
+}
+
+func TestOrderTerms(t *testing.T) {
+	c := qt.New(t)
+
+	terms := map[string]int{"hugo": 3, "go": 5, "css": 3, "html": 0, "js": 1}
+
+	names := func(entries []helpers.TermEntry) []string {
+		var s []string
+		for _, e := range entries {
+			s = append(s, e.Name)
+		}
+		return s
+	}
+
+	c.Assert(names(helpers.OrderTerms(terms, "count")), qt.DeepEquals, []string{"go", "css", "hugo", "js", "html"})
+	c.Assert(names(helpers.OrderTerms(terms, "weight")), qt.DeepEquals, []string{"js", "css", "hugo", "go", "html"})
+	c.Assert(names(helpers.OrderTerms(terms, "alphabetical")), qt.DeepEquals, []string{"css", "go", "html", "hugo", "js"})
+	c.Assert(names(helpers.OrderTerms(terms, "")), qt.DeepEquals, []string{"css", "go", "html", "hugo", "js"})
+
+	c.Assert(helpers.OrderTerms(terms, "count")[0], qt.Equals, helpers.TermEntry{Name: "go", Value: 5})
+	c.Assert(helpers.OrderTerms(nil, "count"), qt.HasLen, 0)
+
+	// Ties are broken by name.
+	tied := map[string]int{"c": 1, "a": 1, "b": 1}
+	for _, by := range []string{"count", "weight", "alphabetical"} {
+		c.Assert(names(helpers.OrderTerms(tied, by)), qt.DeepEquals, []string{"a", "b", "c"}, qt.Commentf(by))
+	}
+}
+
+func TestTagCloudScale(t *testing.T) {
+	c := qt.New(t)
+
+	// Skewed: 1000 is 10 times 100 which is 10 times 10, so on a log scale
+	// they are evenly spread.
+	sizes := helpers.TagCloudScale(map[string]int{"go": 1000, "hugo": 100, "css": 10, "js": 1}, 10, 40)
+	c.Assert(sizes, qt.DeepEquals, map[string]int{"go": 40, "hugo": 30, "css": 20, "js": 10})
+
+	// On a linear scale these would all be 10 or 11.
+	sizes = helpers.TagCloudScale(map[string]int{"a": 1000, "b": 3, "c": 2, "d": 1}, 10, 40)
+	c.Assert(sizes["a"], qt.Equals, 40)
+	c.Assert(sizes["b"] > sizes["c"] && sizes["c"] > sizes["d"], qt.IsTrue)
+	c.Assert(sizes["d"], qt.Equals, 10)
+
+	// Uniform.
+	sizes = helpers.TagCloudScale(map[string]int{"a": 5, "b": 5, "c": 5}, 10, 20)
+	c.Assert(sizes, qt.DeepEquals, map[string]int{"a": 15, "b": 15, "c": 15})
+	c.Assert(helpers.TagCloudScale(map[string]int{"a": 7}, 10, 20), qt.DeepEquals, map[string]int{"a": 15})
+
+	c.Assert(helpers.TagCloudScale(nil, 10, 20), qt.HasLen, 0)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"strings"
+	"sync"
+)
+
+// Tokenizer splits text into words, e.g. for summary extraction and
+// reading time calculation.
+type Tokenizer interface {
+	Tokenize(s string) []string
+}
+
+// TokenizerFunc is an adapter to allow the use of ordinary functions as
+// Tokenizers.
+type TokenizerFunc func(s string) []string
+
+// Tokenize calls f(s).
+func (f TokenizerFunc) Tokenize(s string) []string {
+	return f(s)
+}
+
+// WhitespaceTokenizer is the default Tokenizer. It splits on Unicode
+// whitespace, see IsWhitespaceUnicode.
+var WhitespaceTokenizer Tokenizer = TokenizerFunc(func(s string) []string {
+	return strings.FieldsFunc(s, IsWhitespaceUnicode)
+})
+
+var tokenizers = struct {
+	sync.RWMutex
+	m map[string]Tokenizer
+}{m: make(map[string]Tokenizer)}
+
+// RegisterTokenizer registers t as the Tokenizer to use for the language
+// with the given code, e.g. "th" or "zh-cn". Language codes are case
+// insensitive. A nil t removes any existing registration.
+func RegisterTokenizer(lang string, t Tokenizer) {
+	lang = strings.ToLower(lang)
+	tokenizers.Lock()
+	defer tokenizers.Unlock()
+	if t == nil {
+		delete(tokenizers.m, lang)
+		return
+	}
+	tokenizers.m[lang] = t
+}
+
+// GetTokenizer returns the Tokenizer registered for lang. If there's no
+// exact match for a regional code such as "zh-tw", the base language ("zh")
+// is tried before falling back to WhitespaceTokenizer.
+func GetTokenizer(lang string) Tokenizer {
+	lang = strings.ToLower(lang)
+	tokenizers.RLock()
+	defer tokenizers.RUnlock()
+	if t, found := tokenizers.m[lang]; found {
+		return t
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		if t, found := tokenizers.m[lang[:i]]; found {
+			return t
+		}
+	}
+	return WhitespaceTokenizer
+}
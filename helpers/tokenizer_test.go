@@ -0,0 +1,55 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+func TestWhitespaceTokenizer(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.WhitespaceTokenizer.Tokenize(" Hello,\tworld and\u3000more\u200bwords\n"), qt.DeepEquals,
+		[]string{"Hello,", "world", "and", "more", "words"})
+	c.Assert(helpers.WhitespaceTokenizer.Tokenize("  "), qt.HasLen, 0)
+}
+
+func TestGetTokenizer(t *testing.T) {
+	c := qt.New(t)
+
+	runes := helpers.TokenizerFunc(func(s string) []string {
+		var words []string
+		for _, r := range s {
+			words = append(words, string(r))
+		}
+		return words
+	})
+
+	helpers.RegisterTokenizer("TH", runes)
+	defer helpers.RegisterTokenizer("th", nil)
+
+	c.Assert(helpers.GetTokenizer("th").Tokenize("สวัสดี"), qt.HasLen, 6)
+	c.Assert(helpers.GetTokenizer("th-TH").Tokenize("สวัสดี"), qt.HasLen, 6)
+
+	// No registration, fall through to the default.
+	for _, lang := range []string{"en", "zh-cn", ""} {
+		c.Assert(helpers.GetTokenizer(lang).Tokenize("a b c"), qt.DeepEquals, []string{"a", "b", "c"}, qt.Commentf(lang))
+	}
+
+	helpers.RegisterTokenizer("th", nil)
+	c.Assert(helpers.GetTokenizer("th").Tokenize("สวัสดี"), qt.DeepEquals, []string{"สวัสดี"})
+}
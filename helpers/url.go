@@ -18,6 +18,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/gohugoio/hugo/common/paths"
 
@@ -69,6 +70,17 @@ func SanitizeURLKeepTrailingSlash(in string) string {
 	return sanitizeURLWithFlags(in, purell.FlagsSafe|purell.FlagRemoveDotSegments|purell.FlagRemoveDuplicateSlashes|purell.FlagRemoveUnnecessaryHostDots|purell.FlagRemoveEmptyPortSeparator)
 }
 
+// CanonicalizeURL returns the canonical form of in for comparison, e.g. to
+// match a menu entry's URL against the current page. On top of what
+// SanitizeURL does, it removes the fragment and any directory index, e.g.
+// "index.html", and sorts the query parameters, so e.g.
+// "https://Example.org:443/posts/index.html#top" and "https://example.org/posts/"
+// both become "https://example.org/posts".
+func CanonicalizeURL(in string) string {
+	return sanitizeURLWithFlags(in, purell.FlagsSafe|purell.FlagRemoveTrailingSlash|purell.FlagRemoveDotSegments|purell.FlagRemoveDuplicateSlashes|purell.FlagRemoveUnnecessaryHostDots|purell.FlagRemoveEmptyPortSeparator|
+		purell.FlagRemoveFragment|purell.FlagRemoveDirectoryIndex|purell.FlagSortQuery)
+}
+
 // URLize is similar to MakePath, but with Unicode handling
 // Example:
 //
@@ -218,3 +230,76 @@ func (p *PathSpec) PrependBasePath(rel string, isAbs bool) string {
 	}
 	return rel
 }
+
+// ParseLinkHeader parses a RFC 5988 Link header, e.g.
+//
+//	<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=5>; rel="last"
+//
+// and returns a map of rel to URL. A link with multiple space separated
+// relation types gets an entry for each of them.
+// Malformed entries are skipped.
+func ParseLinkHeader(header string) map[string]string {
+	links := make(map[string]string)
+
+	for _, link := range splitLinkHeader(header) {
+		link = strings.TrimSpace(link)
+		if !strings.HasPrefix(link, "<") {
+			continue
+		}
+		end := strings.Index(link, ">")
+		if end == -1 {
+			continue
+		}
+		target := strings.TrimSpace(link[1:end])
+		if target == "" {
+			continue
+		}
+
+		for _, param := range strings.Split(link[end+1:], ";") {
+			key, val, found := strings.Cut(param, "=")
+			if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+				continue
+			}
+			val = strings.Trim(strings.TrimSpace(val), `"`)
+			for _, rel := range strings.Fields(val) {
+				rel = strings.ToLower(rel)
+				if _, exists := links[rel]; !exists {
+					links[rel] = target
+				}
+			}
+		}
+	}
+
+	return links
+}
+
+// splitLinkHeader splits a Link header on the commas separating the links,
+// ignoring commas inside the URL brackets and quoted parameter values.
+func splitLinkHeader(header string) []string {
+	var (
+		parts    []string
+		inURL    bool
+		inQuotes bool
+		start    int
+	)
+
+	for i, r := range header {
+		switch {
+		case r == '<' && !inQuotes:
+			inURL = true
+		case r == '>' && !inQuotes:
+			inURL = false
+		case r == '"' && !inURL:
+			inQuotes = !inQuotes
+		case r == ',' && !inURL && !inQuotes:
+			parts = append(parts, header[start:i])
+			start = i + 1
+		}
+	}
+
+	if rest := strings.TrimFunc(header[start:], unicode.IsSpace); rest != "" {
+		parts = append(parts, rest)
+	}
+
+	return parts
+}
@@ -262,3 +262,48 @@ func TestSanitizeURL(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLinkHeader(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(helpers.ParseLinkHeader(
+		`<https://api.example.com/items?page=2&per_page=10>; rel="next", <https://api.example.com/items?page=5>; rel="last"; title="Last, page"`),
+		qt.DeepEquals, map[string]string{
+			"next": "https://api.example.com/items?page=2&per_page=10",
+			"last": "https://api.example.com/items?page=5",
+		})
+
+	c.Assert(helpers.ParseLinkHeader(`<https://example.com/a,b>; rel=prev`), qt.DeepEquals, map[string]string{
+		"prev": "https://example.com/a,b",
+	})
+
+	c.Assert(helpers.ParseLinkHeader(`<https://example.com/1>; rel="first prev"`), qt.DeepEquals, map[string]string{
+		"first": "https://example.com/1",
+		"prev":  "https://example.com/1",
+	})
+
+	c.Assert(helpers.ParseLinkHeader(
+		`https://example.com/nobrackets; rel="next", <https://example.com/norel>, <>; rel="last", <https://example.com/ok>; REL="Next"`),
+		qt.DeepEquals, map[string]string{
+			"next": "https://example.com/ok",
+		})
+
+	c.Assert(helpers.ParseLinkHeader(""), qt.HasLen, 0)
+}
+
+func TestCanonicalizeURL(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect string
+	}{
+		{"https://Example.org:443/posts/index.html#top", "https://example.org/posts"},
+		{"https://example.org/posts/", "https://example.org/posts"},
+		{"/posts/", "/posts"},
+		{"/posts/foo.html", "/posts/foo.html"},
+		{"/a/?b=1&a=2", "/a?a=2&b=1"},
+	} {
+		c.Assert(helpers.CanonicalizeURL(test.in), qt.Equals, test.expect)
+	}
+}
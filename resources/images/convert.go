@@ -0,0 +1,61 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	// Register the WebP decoder; the other formats Hugo can read are
+	// registered by the encoder imports in image.go.
+	_ "golang.org/x/image/webp"
+
+	"github.com/bep/gowebp/libwebp/webpoptions"
+	"github.com/gohugoio/hugo/common/herrors"
+	"github.com/gohugoio/hugo/resources/images/webp"
+)
+
+// ConvertImageFormat decodes the image in r, in any of the formats Hugo can
+// read, and encodes it as targetFormat with the given quality (1-100).
+// The only supported target format is "webp", which is only available in the
+// extended version of Hugo; herrors.ErrFeatureNotAvailable is returned if it
+// can't be encoded in this build.
+func ConvertImageFormat(r io.Reader, targetFormat string, quality int) ([]byte, error) {
+	if quality < 1 || quality > 100 {
+		return nil, fmt.Errorf("image quality must be between 1 and 100, got %d", quality)
+	}
+	if !strings.EqualFold(targetFormat, "webp") {
+		return nil, fmt.Errorf("unsupported target image format %q, only webp is supported", targetFormat)
+	}
+	if !webp.Supports() {
+		return nil, herrors.ErrFeatureNotAvailable
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := webp.Encode(&buf, img, webpoptions.EncodingOptions{
+		Quality:     quality,
+		UseSharpYuv: true,
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/herrors"
+	"github.com/gohugoio/hugo/resources/images/webp"
+)
+
+func TestConvertImageFormat(t *testing.T) {
+	c := qt.New(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x * 6), uint8(y * 8), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	c.Assert(png.Encode(&buf, img), qt.IsNil)
+	pngBytes := buf.Bytes()
+
+	b, err := ConvertImageFormat(bytes.NewReader(pngBytes), "webp", 80)
+	if !webp.Supports() {
+		c.Assert(err, qt.Equals, herrors.ErrFeatureNotAvailable)
+	} else {
+		c.Assert(err, qt.IsNil)
+		config, format, err := image.DecodeConfig(bytes.NewReader(b))
+		c.Assert(err, qt.IsNil)
+		c.Assert(format, qt.Equals, "webp")
+		c.Assert(config.Width, qt.Equals, 40)
+		c.Assert(config.Height, qt.Equals, 30)
+	}
+
+	_, err = ConvertImageFormat(bytes.NewReader(pngBytes), "webp", 0)
+	c.Assert(err, qt.ErrorMatches, "image quality must be between 1 and 100, got 0")
+	_, err = ConvertImageFormat(bytes.NewReader(pngBytes), "avif", 80)
+	c.Assert(err, qt.ErrorMatches, `unsupported target image format "avif", only webp is supported`)
+	_, err = ConvertImageFormat(bytes.NewReader(pngBytes), "heic", 80)
+	c.Assert(err, qt.ErrorMatches, `unsupported target image format "heic".*`)
+	if webp.Supports() {
+		_, err = ConvertImageFormat(strings.NewReader("not an image"), "webp", 80)
+		c.Assert(err, qt.ErrorMatches, "failed to decode image.*")
+	}
+}